@@ -8,6 +8,6 @@ import (
 
 func main() {
 	if err := koyeb.Run(); err != nil {
-		os.Exit(1)
+		os.Exit(koyeb.ExitCode(err))
 	}
 }
@@ -64,14 +64,17 @@ func NewAppCmd() *cobra.Command {
 	}
 	initAppCmd.Flags().Bool("wait", false, "Waits until app deployment is done")
 	initAppCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
+	initAppCmd.Flags().Bool("logs", false, "Tail the build logs, then the runtime logs of the deployment while waiting. Implies --wait")
 	appCmd.AddCommand(initAppCmd)
 	serviceHandler.addServiceDefinitionFlags(initAppCmd.Flags())
+	initAppCmd.RegisterFlagCompletionFunc("type", completeServiceTypes) //nolint:errcheck
 
 	getAppCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get app",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Get),
+		Use:               "get NAME",
+		Short:             "Get app",
+		ValidArgsFunction: completeArgs(completeApps),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Get),
 	}
 	appCmd.AddCommand(getAppCmd)
 
@@ -83,17 +86,19 @@ func NewAppCmd() *cobra.Command {
 	appCmd.AddCommand(listAppCmd)
 
 	describeAppCmd := &cobra.Command{
-		Use:   "describe NAME",
-		Short: "Describe app",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Describe),
+		Use:               "describe NAME",
+		Short:             "Describe app",
+		ValidArgsFunction: completeArgs(completeApps),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Describe),
 	}
 	appCmd.AddCommand(describeAppCmd)
 
 	updateAppCmd := &cobra.Command{
-		Use:   "update NAME",
-		Short: "Update app",
-		Args:  cobra.ExactArgs(1),
+		Use:               "update NAME",
+		Short:             "Update app",
+		ValidArgsFunction: completeArgs(completeApps),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			updateApp := koyeb.NewUpdateAppWithDefaults()
 			SyncFlags(cmd, args, updateApp)
@@ -133,29 +138,55 @@ func NewAppCmd() *cobra.Command {
 	appCmd.AddCommand(updateAppCmd)
 
 	deleteAppCmd := &cobra.Command{
-		Use:   "delete NAME",
-		Short: "Delete app",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Delete),
+		Use:               "delete NAME",
+		Short:             "Delete app",
+		ValidArgsFunction: completeArgs(completeApps),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Delete),
 	}
 	appCmd.AddCommand(deleteAppCmd)
 
 	pauseServiceCmd := &cobra.Command{
-		Use:   "pause NAME",
-		Short: "Pause app",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Pause),
+		Use:               "pause NAME",
+		Short:             "Pause app",
+		ValidArgsFunction: completeArgs(completeApps),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Pause),
 	}
 	appCmd.AddCommand(pauseServiceCmd)
 
 	resumeServiceCmd := &cobra.Command{
-		Use:   "resume NAME",
-		Short: "Resume app",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Resume),
+		Use:               "resume NAME",
+		Short:             "Resume app",
+		ValidArgsFunction: completeArgs(completeApps),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Resume),
 	}
 	appCmd.AddCommand(resumeServiceCmd)
 
+	cloneAppCmd := &cobra.Command{
+		Use:               "clone SOURCE DESTINATION",
+		Short:             "Create a copy of an app and its services",
+		ValidArgsFunction: completeArgs(completeApps),
+		Long: `Create the application DESTINATION with a copy of each service of the application SOURCE.
+
+The services are cloned from the definition of their latest deployment. Regions, instance types and environment variables can be changed with --map-region, --map-instance-type and --env, which apply to all the services. Databases are not cloned.
+
+Volumes are not cloned by default. Use --volumes recreate to create new empty volumes with the same size. Custom domains can't be attached to two applications: use --domain to attach a new domain to DESTINATION in place of a domain of SOURCE.`,
+		Args: cobra.ExactArgs(2),
+		Example: `
+# Create the application staging with the same services as prod
+$> koyeb app clone prod staging
+
+# Preview the clone of the application in another region, with new volumes and a new domain
+$> koyeb app clone prod staging --map-region fra:was --volumes recreate --domain www.example.com=staging.example.com --dry-run
+`,
+		RunE: WithCLIContext(h.Clone),
+	}
+	addCloneFlags(cloneAppCmd.Flags())
+	cloneAppCmd.Flags().StringSlice("domain", nil, "Attach the domain NEW to the clone in place of the domain OLD of the source, in the format OLD=NEW. Can be specified multiple times")
+	appCmd.AddCommand(cloneAppCmd)
+
 	return appCmd
 }
 
@@ -0,0 +1,219 @@
+package koyeb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func (h *AppHandler) Clone(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	opts, err := parseCloneOptions(cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	domainFlags, _ := cmd.Flags().GetStringSlice("domain")
+	domains := map[string]string{}
+	for _, value := range domainFlags {
+		from, to, found := strings.Cut(value, "=")
+		if !found || from == "" || to == "" {
+			return &errors.CLIError{
+				What:       "Error while parsing the --domain flag",
+				Why:        fmt.Sprintf("the value %q is invalid", value),
+				Additional: []string{"The value must be in the format OLD=NEW"},
+				Orig:       nil,
+				Solution:   "Fix the flag and try again",
+			}
+		}
+		domains[from] = to
+	}
+
+	reply, _, err := h.cloneApp(ctx, args[0], args[1], opts, domains)
+	// When some services could not be cloned, display the resources which were
+	if reply != nil {
+		ctx.Renderer.Render(reply)
+	}
+	return err
+}
+
+// cloneApp creates the application dstName with a copy of the services of the
+// application sourceName. The custom domains listed in domains are recreated
+// with their new name. It returns the list of cloned resources, and the ID of
+// the new application, which is empty with opts.dryRun.
+//
+// The services which can not be cloned do not stop the clone of the others.
+// The error then lists the services which were and were not cloned, and the
+// reply the resources created.
+func (h *AppHandler) cloneApp(ctx *CLIContext, sourceName, dstName string, opts *cloneOptions, domains map[string]string) (*CloneReply, string, error) {
+	serviceHandler := NewServiceHandler()
+
+	source, err := h.ResolveAppArgs(ctx, sourceName)
+	if err != nil {
+		return nil, "", err
+	}
+	resApp, resp, err := ctx.Client.AppsApi.GetApp(ctx.Context, source).Execute()
+	if err != nil {
+		return nil, "", errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while retrieving the application `%s`", sourceName),
+			err,
+			resp,
+		)
+	}
+
+	services, err := listAppServices(ctx, source, sourceName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	customDomains, err := listAppCustomDomains(ctx, source, sourceName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	reply := NewCloneReply(opts.dryRun)
+	reply.add("app", resApp.App.GetName(), dstName, "")
+
+	dstApp := ""
+	if !opts.dryRun {
+		createApp := koyeb.NewCreateAppWithDefaults()
+		createApp.SetName(dstName)
+		if opts.appLifeCycle != nil {
+			createApp.SetLifeCycle(*opts.appLifeCycle)
+		} else if resApp.App.HasLifeCycle() {
+			createApp.SetLifeCycle(resApp.App.GetLifeCycle())
+		}
+		res, resp, err := ctx.Client.AppsApi.CreateApp(ctx.Context).App(*createApp).Execute()
+		if err != nil {
+			return nil, "", errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while creating the application `%s`", dstName),
+				err,
+				resp,
+			)
+		}
+		dstApp = res.App.GetId()
+	}
+
+	cloned := []string{}
+	failed := []string{}
+	for _, service := range services {
+		serviceName := fmt.Sprintf("%s/%s", resApp.App.GetName(), service.GetName())
+		if service.GetType() == koyeb.SERVICETYPE_DATABASE {
+			log.Warnf("The database `%s` is not cloned. Create a new database with `koyeb database create`.", serviceName)
+			continue
+		}
+		if err := serviceHandler.cloneService(ctx, service.GetId(), serviceName, dstApp, dstName, service.GetName(), opts, reply); err != nil {
+			log.Errorf("Unable to clone the service `%s`: %s", serviceName, err)
+			failed = append(failed, serviceName)
+			continue
+		}
+		cloned = append(cloned, serviceName)
+	}
+
+	for _, domain := range customDomains {
+		newName, ok := domains[domain.GetName()]
+		if !ok {
+			log.Warnf("The domain %s is not cloned. Use --domain %s=NEW_DOMAIN to attach a new domain to the application.", domain.GetName(), domain.GetName())
+			continue
+		}
+		reply.add("domain", domain.GetName(), newName, "")
+		if opts.dryRun {
+			continue
+		}
+
+		createDomain := koyeb.NewCreateDomainWithDefaults()
+		createDomain.SetName(newName)
+		createDomain.SetType(koyeb.DOMAINTYPE_CUSTOM)
+		createDomain.SetAppId(dstApp)
+		_, resp, err := ctx.Client.DomainsApi.CreateDomain(ctx.Context).Domain(*createDomain).Execute()
+		if err != nil {
+			return reply, dstApp, errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while creating the domain `%s`", newName),
+				err,
+				resp,
+			)
+		}
+	}
+
+	if len(failed) > 0 {
+		if len(cloned) == 0 {
+			cloned = append(cloned, "none")
+		}
+		return reply, dstApp, &errors.CLIError{
+			What: fmt.Sprintf("Error while cloning the application `%s`", sourceName),
+			Why:  fmt.Sprintf("%d of the services could not be cloned", len(failed)),
+			Additional: []string{
+				fmt.Sprintf("Services cloned: %s", strings.Join(cloned, ", ")),
+				fmt.Sprintf("Services not cloned: %s", strings.Join(failed, ", ")),
+			},
+			Orig:     nil,
+			Solution: errors.CLIErrorSolution(fmt.Sprintf("Fix the errors above, then clone the missing services to the application `%s` with `koyeb service clone`, or delete it with `koyeb app delete` and try again", dstName)),
+		}
+	}
+	return reply, dstApp, nil
+}
+
+// listAppServices returns all the services of the application.
+func listAppServices(ctx *CLIContext, appID string, appName string) ([]koyeb.ServiceListItem, error) {
+	services := []koyeb.ServiceListItem{}
+	page := int64(0)
+	offset := int64(0)
+	limit := int64(100)
+	for {
+		res, resp, err := ctx.Client.ServicesApi.ListServices(ctx.Context).
+			AppId(appID).
+			Offset(strconv.FormatInt(offset, 10)).
+			Limit(strconv.FormatInt(limit, 10)).
+			Execute()
+		if err != nil {
+			return nil, errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while listing the services of the application `%s`", appName),
+				err,
+				resp,
+			)
+		}
+		services = append(services, res.GetServices()...)
+
+		page++
+		offset = page * limit
+		if offset >= res.GetCount() {
+			break
+		}
+	}
+	return services, nil
+}
+
+// listAppCustomDomains returns all the custom domains of the application.
+func listAppCustomDomains(ctx *CLIContext, appID string, appName string) ([]koyeb.Domain, error) {
+	domains := []koyeb.Domain{}
+	page := int64(0)
+	offset := int64(0)
+	limit := int64(100)
+	for {
+		res, resp, err := ctx.Client.DomainsApi.ListDomains(ctx.Context).
+			AppIds([]string{appID}).
+			Types([]string{string(koyeb.DOMAINTYPE_CUSTOM)}).
+			Offset(strconv.FormatInt(offset, 10)).
+			Limit(strconv.FormatInt(limit, 10)).
+			Execute()
+		if err != nil {
+			return nil, errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while listing the domains of the application `%s`", appName),
+				err,
+				resp,
+			)
+		}
+		domains = append(domains, res.GetDomains()...)
+
+		page++
+		offset = page * limit
+		if offset >= res.GetCount() {
+			break
+		}
+	}
+	return domains, nil
+}
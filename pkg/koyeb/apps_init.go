@@ -14,7 +14,8 @@ import (
 )
 
 func (h *AppHandler) Init(ctx *CLIContext, cmd *cobra.Command, args []string, createApp *koyeb.CreateApp, createService *koyeb.CreateService) error {
-	wait, _ := cmd.Flags().GetBool("wait")
+	// --logs implies --wait
+	wait := GetBoolFlags(cmd, "wait") || GetBoolFlags(cmd, "logs")
 	waitTimeout, err := cmd.Flags().GetDuration("wait-timeout")
 	if err != nil {
 		return err
@@ -75,6 +76,11 @@ func (h *AppHandler) Init(ctx *CLIContext, cmd *cobra.Command, args []string, cr
 		ctxd, cancel := context.WithTimeout(ctx.Context, waitTimeout)
 		defer cancel()
 
+		if GetBoolFlags(cmd, "logs") {
+			follower := FollowDeploymentLogs(ctx, serviceRes.Service.GetLatestDeploymentId(), GetBoolFlags(cmd, "full"))
+			defer follower.Stop()
+		}
+
 		for range ticker(ctxd, 2*time.Second) {
 			getServiceRes, resp, err := ctx.Client.ServicesApi.GetService(ctxd, serviceRes.Service.GetId()).Execute()
 			if err != nil {
@@ -6,80 +6,72 @@ package archive
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
-type tarball struct {
-	File *os.File
+// Options configures the content and the compression of an archive.
+type Options struct {
+	// IgnoreDirectories are the base names of the directories which are not
+	// included in the archive, e.g. .git, node_modules or vendor
+	IgnoreDirectories []string
+	// Ignore matches the files and directories which are not included in the archive
+	Ignore *IgnoreMatcher
+	// CompressionLevel is the gzip compression level. As for compress/gzip,
+	// the zero value means no compression: use gzip.DefaultCompression for the
+	// default level.
+	CompressionLevel int
 }
 
-func (t *tarball) Close() error {
-	log.Debugf("Remove temporary archive file %s", t.File.Name())
-	if err := os.Remove(t.File.Name()); err != nil {
-		return err
-	}
-	return t.File.Close()
-}
-
-// Archive compresses a directory into a tarball and returns the path to this tarball.
-// Some directories are ignored by default (e.g. .git, node_modules, vendor).
-// This is not yet configurable but could be in the future.
-func Archive(path string, ignoreDirectories []string) (*tarball, error) {
+// reproducibleModTime is the modification time of all the entries of the
+// archive. It is not the zero time because some tools reject dates before 1980.
+var reproducibleModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Write compresses a directory into a tarball written to w. The tarball is
+// streamed: the directory is never archived to a temporary file.
+//
+// Archives are reproducible: entries are sorted by name since filepath.Walk
+// walks the files in lexical order, and the modification times and owners are
+// normalized, so archiving the same files twice produces the same tarball.
+func Write(w io.Writer, path string, opts Options) error {
 	basePath, err := filepath.Abs(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	tempFile, err := os.CreateTemp("", "koyeb-archive-*.tar.gz")
+	// Compress the tarball
+	gzipWriter, err := gzip.NewWriterLevel(w, opts.CompressionLevel)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	log.Debugf("Create temporary archive file %s", tempFile.Name())
-
-	tarball := tarball{tempFile}
-
-	// Compress the tarball
-	gzipWriter := gzip.NewWriter(tempFile)
-	defer gzipWriter.Close()
 
 	// Create a new tar archive
 	tarWriter := tar.NewWriter(gzipWriter)
-	defer tarWriter.Close()
-
-	if err := filepath.Walk(basePath, func(file string, fi os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Ignore directories that should not be included in the archive. We
-		// only match the base name of the directory, there is no yet support
-		// for regex or more complex patterns, that could be useful to ignore
-		// all the files from .gitignore or .dockerignore for example
-		if fi.IsDir() && slices.Contains(ignoreDirectories, filepath.Base(file)) {
-			log.Debugf("Archive: skip %s", file)
-			return filepath.SkipDir
-		}
 
+	if err := walk(basePath, opts.IgnoreDirectories, opts.Ignore, func(file string, relativePath string, fi os.FileInfo) error {
 		log.Debugf("Archive %s", file)
 
-		relativePath, err := filepath.Rel(basePath, file)
-		if err != nil {
-			return fmt.Errorf("unable to get relative path for file '%s': %w", file, err)
-		}
-
 		// Create header
 		header, err := tar.FileInfoHeader(fi, "")
 		if err != nil {
 			return fmt.Errorf("unable to create header for file '%s': %w", file, err)
 		}
 
-		header.Name = filepath.ToSlash(relativePath)
+		header.Name = relativePath
+		header.ModTime = reproducibleModTime
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
 
 		// Write header
 		if err := tarWriter.WriteHeader(header); err != nil {
@@ -99,8 +91,124 @@ func Archive(path string, ignoreDirectories []string) (*tarball, error) {
 		}
 		return nil
 	}); err != nil {
-		tarball.Close() // Remove the temporary file in case of error
-		return nil, err
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// Stat archives a directory without storing the tarball, and returns the size
+// and the SHA-256 of the tarball. Since archives are reproducible, calling
+// Write afterwards produces a tarball of this size and hash, unless the files
+// changed in the meantime.
+func Stat(path string, opts Options) (int64, string, error) {
+	hash := sha256.New()
+	counter := &countingWriter{}
+	if err := Write(io.MultiWriter(hash, counter), path, opts); err != nil {
+		return 0, "", err
+	}
+	return counter.n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Fingerprint returns a hash of the metadata of the files included in the
+// archive of path: their names, modes, sizes and modification times. It does
+// not read the files, so it is much faster than Stat, and it changes when a
+// file is added, removed or modified. It identifies the archive of a
+// directory which did not change, without archiving it.
+func Fingerprint(path string, opts Options) (string, error) {
+	basePath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%d\n", basePath, opts.CompressionLevel)
+	if err := walk(basePath, opts.IgnoreDirectories, opts.Ignore, func(file string, relativePath string, fi os.FileInfo) error {
+		fmt.Fprintf(hash, "%s\x00%o\x00%d\x00%d\n", relativePath, fi.Mode(), fi.Size(), fi.ModTime().UnixNano())
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// Excludes returns true if the file or directory, whose path is relative to
+// the root of the archive and uses / as separator, is not included in the
+// archive because it or one of its parent directories is ignored. With the
+// .dockerignore syntax, the full path is matched against the patterns, so a
+// file of an ignored directory can be included again by a negation pattern.
+func (o Options) Excludes(relativePath string, isDir bool) bool {
+	parts := strings.Split(relativePath, "/")
+	for i := range parts {
+		dir := i < len(parts)-1 || isDir
+		if dir && slices.Contains(o.IgnoreDirectories, parts[i]) {
+			return true
+		}
+		if !o.Ignore.isDocker() && o.Ignore.Match(strings.Join(parts[:i+1], "/"), dir) {
+			return true
+		}
+	}
+	return o.Ignore.isDocker() && o.Ignore.Match(relativePath, isDir)
+}
+
+// SkipsDir returns true if the directory and all its content are excluded, so
+// it does not need to be walked. It differs from Excludes when a negation
+// pattern of a .dockerignore file can include again a file of the directory.
+func (o Options) SkipsDir(relativePath string) bool {
+	for _, part := range strings.Split(relativePath, "/") {
+		if slices.Contains(o.IgnoreDirectories, part) {
+			return true
+		}
 	}
-	return &tarball, nil
+	return o.Excludes(relativePath, true) && !o.Ignore.Reincludes(relativePath)
+}
+
+// walk calls fn for each file and directory of basePath which is not ignored,
+// with its path relative to basePath using / as separator. Directories whose
+// base name is in ignoreDirectories are skipped, as well as the files and
+// directories matched by ignore.
+func walk(basePath string, ignoreDirectories []string, ignore *IgnoreMatcher, fn func(file string, relativePath string, fi os.FileInfo) error) error {
+	return filepath.Walk(basePath, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Ignore directories that should not be included in the archive. We
+		// only match the base name of the directory.
+		if fi.IsDir() && slices.Contains(ignoreDirectories, filepath.Base(file)) {
+			log.Debugf("Archive: skip %s", file)
+			return filepath.SkipDir
+		}
+
+		relativePath, err := filepath.Rel(basePath, file)
+		if err != nil {
+			return fmt.Errorf("unable to get relative path for file '%s': %w", file, err)
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		// Ignore the files and directories matched by the ignore file. The
+		// root directory is always included.
+		if relativePath != "." && ignore.Match(relativePath, fi.IsDir()) {
+			log.Debugf("Archive: skip %s (ignore file)", file)
+			if fi.IsDir() && !ignore.Reincludes(relativePath) {
+				return filepath.SkipDir
+			}
+			// The directory is walked, because a file it contains can be
+			// included again, but it is not archived itself
+			return nil
+		}
+		return fn(file, relativePath, fi)
+	})
 }
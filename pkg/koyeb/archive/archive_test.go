@@ -0,0 +1,99 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveReproducible(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a/c.txt", "a/d.txt"} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	hash := func() string {
+		var buf bytes.Buffer
+		assert.NoError(t, Write(&buf, dir, Options{CompressionLevel: gzip.DefaultCompression}))
+
+		size, hash, err := Stat(dir, Options{CompressionLevel: gzip.DefaultCompression})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), size)
+		return hash
+	}
+
+	first := hash()
+
+	// Touching the files does not change the archive
+	later := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "b.txt"), later, later))
+	assert.Equal(t, first, hash())
+
+	// Changing the content does
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a/c.txt"), []byte("changed"), 0644))
+	assert.NotEqual(t, first, hash())
+}
+
+func TestArchiveDockerIgnoreReinclude(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.js", "node_modules/keep", "node_modules/lib/index.js"} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+	matcher, err := NewDockerIgnoreMatcher(strings.NewReader("node_modules\n!node_modules/keep\n"))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, dir, Options{Ignore: matcher}))
+
+	gzipReader, err := gzip.NewReader(&buf)
+	assert.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+	names := []string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{".", "main.js", "node_modules/keep"}, names)
+}
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.js")
+	assert.NoError(t, os.WriteFile(path, []byte("v1"), 0644))
+
+	fingerprint := func() string {
+		value, err := Fingerprint(dir, Options{})
+		assert.NoError(t, err)
+		return value
+	}
+
+	first := fingerprint()
+	assert.Equal(t, first, fingerprint())
+
+	// Modifying a file changes the fingerprint
+	later := time.Now().Add(time.Hour)
+	assert.NoError(t, os.WriteFile(path, []byte("v2"), 0644))
+	assert.NoError(t, os.Chtimes(path, later, later))
+	second := fingerprint()
+	assert.NotEqual(t, first, second)
+
+	// Adding a file too
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.js"), nil, 0644))
+	assert.NotEqual(t, second, fingerprint())
+}
@@ -0,0 +1,283 @@
+package archive
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFiles are the files read by LoadIgnoreFile, by order of preference.
+var IgnoreFiles = []string{".koyebignore", ".dockerignore", ".gitignore"}
+
+// IgnoreMatcher matches paths against a list of patterns using the syntax of
+// .gitignore files:
+//
+//   - blank lines and lines starting with # are ignored
+//   - a pattern starting with ! includes again the paths excluded by a previous pattern
+//   - a pattern ending with / only matches directories
+//   - a pattern containing a / at the beginning or in the middle is relative to
+//     the root of the archive, otherwise it matches at any level
+//   - * matches anything except /, ? matches any character except /, and
+//     [a-z] matches a range of characters
+//   - ** matches any number of directories, for example **/build, docs/** or a/**/b
+//
+// As with git, it is not possible to include again a file if one of its parent
+// directories is excluded.
+//
+// The matchers created by NewDockerIgnoreMatcher use the syntax of
+// .dockerignore files instead.
+type IgnoreMatcher struct {
+	patterns []ignorePattern
+	// docker is true for the matchers created by NewDockerIgnoreMatcher
+	docker bool
+}
+
+type ignorePattern struct {
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+	// text is the cleaned pattern of .dockerignore files
+	text string
+}
+
+// NewIgnoreMatcher parses the patterns read from r.
+func NewIgnoreMatcher(r io.Reader) (*IgnoreMatcher, error) {
+	matcher := &IgnoreMatcher{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		pattern, ok := parseIgnorePattern(scanner.Text())
+		if ok {
+			matcher.patterns = append(matcher.patterns, pattern)
+		}
+	}
+	return matcher, scanner.Err()
+}
+
+// NewDockerIgnoreMatcher parses the patterns read from r, using the syntax of
+// .dockerignore files. It differs from the syntax of .gitignore files:
+//
+//   - leading and trailing spaces are ignored, and the patterns are cleaned,
+//     so for example ./build/ is the same pattern as build
+//   - all the patterns are relative to the root of the archive: *.log only
+//     matches the files at the root, and **/*.log matches them at any level
+//   - a pattern ending with / also matches files
+//   - each path is matched against all the patterns, which match the path or
+//     one of its parent directories, and the last matching pattern wins: with
+//     node_modules then !node_modules/keep, node_modules/keep is included
+//     again although its parent directory is excluded
+func NewDockerIgnoreMatcher(r io.Reader) (*IgnoreMatcher, error) {
+	matcher := &IgnoreMatcher{docker: true}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		pattern, ok := parseDockerIgnorePattern(scanner.Text())
+		if ok {
+			matcher.patterns = append(matcher.patterns, pattern)
+		}
+	}
+	return matcher, scanner.Err()
+}
+
+// LoadIgnoreFile reads the first file of IgnoreFiles which exists in dir. It
+// returns the path of the file, or an empty string and a matcher without
+// patterns if none exists. The patterns of .dockerignore use the syntax of
+// Docker, the others the syntax of .gitignore.
+func LoadIgnoreFile(dir string) (*IgnoreMatcher, string, error) {
+	for _, name := range IgnoreFiles {
+		path := filepath.Join(dir, name)
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+
+		parse := NewIgnoreMatcher
+		if name == ".dockerignore" {
+			parse = NewDockerIgnoreMatcher
+		}
+		matcher, err := parse(file)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to read %s: %w", path, err)
+		}
+		return matcher, path, nil
+	}
+	return &IgnoreMatcher{}, "", nil
+}
+
+// Match returns true if the path, relative to the root of the archive and
+// using / as separator, is excluded by the patterns.
+func (m *IgnoreMatcher) Match(path string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+
+	ignored := false
+	for _, pattern := range m.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if pattern.re.MatchString(path) || (m.docker && pattern.matchesParent(path)) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+func (m *IgnoreMatcher) isDocker() bool {
+	return m != nil && m.docker
+}
+
+// Reincludes returns true if a negation pattern can include again a path
+// inside the excluded directory dir. It is always false for the .gitignore
+// syntax. Otherwise, like the Docker builder, the directory needs to be walked
+// if a negation pattern is inside it or contains wildcards.
+func (m *IgnoreMatcher) Reincludes(dir string) bool {
+	if !m.isDocker() {
+		return false
+	}
+	for _, pattern := range m.patterns {
+		if !pattern.negate {
+			continue
+		}
+		if strings.HasPrefix(pattern.text, dir+"/") || strings.ContainsAny(pattern.text, `*?[\`) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesParent returns true if the pattern matches one of the parent
+// directories of path.
+func (p ignorePattern) matchesParent(path string) bool {
+	for idx := strings.LastIndex(path, "/"); idx > 0; idx = strings.LastIndex(path, "/") {
+		path = path[:idx]
+		if p.re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	var pattern ignorePattern
+
+	// Trailing spaces are ignored unless they are escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern, false
+	}
+
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern, false
+	}
+
+	// Patterns with a slash at the beginning or in the middle are relative to
+	// the root. The others match at any level.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	re, err := compileIgnorePattern(line, anchored)
+	if err != nil {
+		// Invalid patterns, for example with an invalid character class, are ignored like git does
+		return pattern, false
+	}
+	pattern.re = re
+	return pattern, true
+}
+
+// parseDockerIgnorePattern parses a line of a .dockerignore file, like the
+// Docker builder does.
+func parseDockerIgnorePattern(line string) (ignorePattern, bool) {
+	var pattern ignorePattern
+
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern, false
+	}
+
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = strings.TrimSpace(line[1:])
+	}
+	if line == "" {
+		return pattern, false
+	}
+	line = strings.TrimPrefix(path.Clean(filepath.ToSlash(line)), "/")
+	if line == "" || line == "." {
+		return pattern, false
+	}
+
+	re, err := compileIgnorePattern(line, true)
+	if err != nil {
+		return pattern, false
+	}
+	pattern.re = re
+	pattern.text = line
+	return pattern, true
+}
+
+// compileIgnorePattern converts a pattern to a regular expression. If anchored
+// is false, the pattern matches at any level.
+func compileIgnorePattern(line string, anchored bool) (*regexp.Regexp, error) {
+	var buf strings.Builder
+	buf.WriteString("^")
+	if !anchored {
+		buf.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			// Leading **/ or /**/ in the middle: zero or more directories
+			buf.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**") && i+2 == len(line):
+			// Trailing /**: everything inside
+			buf.WriteString(".*")
+			i++
+		case c == '*':
+			buf.WriteString("[^/]*")
+		case c == '?':
+			buf.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end < 0 {
+				buf.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(line):
+			i++
+			buf.WriteString(regexp.QuoteMeta(string(line[i])))
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+
+	return regexp.Compile(buf.String())
+}
@@ -0,0 +1,126 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	tests := map[string]struct {
+		patterns string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		"empty":                  {patterns: "", path: "main.go", expected: false},
+		"comment":                {patterns: "# main.go", path: "main.go", expected: false},
+		"basename":               {patterns: "*.log", path: "logs/app.log", expected: true},
+		"basename_no_match":      {patterns: "*.log", path: "app.log.txt", expected: false},
+		"negation":               {patterns: "*.log\n!important.log", path: "important.log", expected: false},
+		"negation_order":         {patterns: "!important.log\n*.log", path: "important.log", expected: true},
+		"dir_only_file":          {patterns: "build/", path: "build", isDir: false, expected: false},
+		"dir_only_dir":           {patterns: "build/", path: "src/build", isDir: true, expected: true},
+		"anchored_root":          {patterns: "/dist", path: "dist", isDir: true, expected: true},
+		"anchored_nested":        {patterns: "/dist", path: "src/dist", isDir: true, expected: false},
+		"anchored_middle":        {patterns: "docs/*.md", path: "docs/README.md", expected: true},
+		"anchored_middle_nested": {patterns: "docs/*.md", path: "src/docs/README.md", expected: false},
+		"star_no_slash":          {patterns: "docs/*.md", path: "docs/api/README.md", expected: false},
+		"leading_double_star":    {patterns: "**/fixtures", path: "a/b/fixtures", isDir: true, expected: true},
+		"leading_double_star_0":  {patterns: "**/fixtures", path: "fixtures", isDir: true, expected: true},
+		"trailing_double_star":   {patterns: "docs/**", path: "docs/api/README.md", expected: true},
+		"middle_double_star":     {patterns: "a/**/b", path: "a/x/y/b", expected: true},
+		"middle_double_star_0":   {patterns: "a/**/b", path: "a/b", expected: true},
+		"question_mark":          {patterns: "file?.txt", path: "file1.txt", expected: true},
+		"class":                  {patterns: "file[0-9].txt", path: "file5.txt", expected: true},
+		"negated_class":          {patterns: "file[!0-9].txt", path: "file5.txt", expected: false},
+		"escaped":                {patterns: `\!important`, path: "!important", expected: true},
+		"trailing_spaces":        {patterns: "*.log   ", path: "app.log", expected: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			matcher, err := NewIgnoreMatcher(strings.NewReader(tc.patterns))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, matcher.Match(tc.path, tc.isDir))
+		})
+	}
+}
+
+func TestDockerIgnoreMatcher(t *testing.T) {
+	tests := map[string]struct {
+		patterns string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		"root":                {patterns: "*.log", path: "app.log", expected: true},
+		"not_nested":          {patterns: "*.log", path: "logs/app.log", expected: false},
+		"double_star":         {patterns: "**/*.log", path: "logs/app.log", expected: true},
+		"double_star_root":    {patterns: "**/*.log", path: "app.log", expected: true},
+		"trailing_slash_file": {patterns: "build/", path: "build", isDir: false, expected: true},
+		"cleaned":             {patterns: "./src/../build", path: "build", isDir: true, expected: true},
+		"leading_slash":       {patterns: "/build", path: "build", isDir: true, expected: true},
+		"spaces":              {patterns: "  *.log  ", path: "app.log", expected: true},
+		"negation":            {patterns: "*.md\n! README.md", path: "README.md", expected: false},
+		"comment":             {patterns: "# app.log", path: "app.log", expected: false},
+		"parent":              {patterns: "node_modules", path: "node_modules/lib/index.js", expected: true},
+		"reinclude":           {patterns: "node_modules\n!node_modules/keep", path: "node_modules/keep", expected: false},
+		"reinclude_other":     {patterns: "node_modules\n!node_modules/keep", path: "node_modules/lib", isDir: true, expected: true},
+		"reinclude_order":     {patterns: "!node_modules/keep\nnode_modules", path: "node_modules/keep", expected: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			matcher, err := NewDockerIgnoreMatcher(strings.NewReader(tc.patterns))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, matcher.Match(tc.path, tc.isDir))
+		})
+	}
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("*.log\n"), 0o644))
+
+	matcher, path, err := LoadIgnoreFile(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".dockerignore"), path)
+	// The patterns of .dockerignore are relative to the root
+	opts := Options{Ignore: matcher}
+	assert.True(t, opts.Excludes("app.log", false))
+	assert.False(t, opts.Excludes("logs/app.log", false))
+}
+
+func TestOptionsExcludes(t *testing.T) {
+	matcher, err := NewIgnoreMatcher(strings.NewReader("*.log\nbuild/\n"))
+	assert.NoError(t, err)
+	opts := Options{IgnoreDirectories: []string{"node_modules"}, Ignore: matcher}
+
+	assert.False(t, opts.Excludes("src/main.go", false))
+	assert.True(t, opts.Excludes("debug.log", false))
+	assert.True(t, opts.Excludes("src/node_modules/a/index.js", false))
+	assert.True(t, opts.Excludes("node_modules", true))
+	assert.False(t, opts.Excludes("node_modules", false))
+	assert.True(t, opts.Excludes("src/build/out.js", false))
+}
+
+func TestOptionsExcludesDockerIgnore(t *testing.T) {
+	matcher, err := NewDockerIgnoreMatcher(strings.NewReader("node_modules\n!node_modules/keep\nbuild\n"))
+	assert.NoError(t, err)
+	opts := Options{IgnoreDirectories: []string{".git"}, Ignore: matcher}
+
+	assert.True(t, opts.Excludes("node_modules", true))
+	assert.True(t, opts.Excludes("node_modules/lib/index.js", false))
+	assert.False(t, opts.Excludes("node_modules/keep", false))
+	assert.True(t, opts.Excludes(".git/keep", false))
+
+	// node_modules is walked to find node_modules/keep, but not build or .git
+	assert.False(t, opts.SkipsDir("node_modules"))
+	assert.True(t, opts.SkipsDir("build"))
+	assert.True(t, opts.SkipsDir(".git"))
+	assert.False(t, opts.SkipsDir("src"))
+}
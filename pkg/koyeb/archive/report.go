@@ -0,0 +1,175 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// LargeBinarySize is the size above which binary files are reported as likely
+// mistakes, for example build artifacts or datasets committed by accident.
+const LargeBinarySize = 50 * 1024 * 1024
+
+// Entry is a file or a directory included in the archive. The size of a
+// directory is the total size of the files it contains.
+type Entry struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	IsDir bool   `json:"is_dir"`
+}
+
+// Report describes the content of the archive of a directory, without
+// uploading it.
+type Report struct {
+	Files       []Entry `json:"files"`
+	Directories []Entry `json:"directories"`
+	// Size is the total size of the files before compression
+	Size int64 `json:"size"`
+	// CompressedSize is the size of the tarball which would be uploaded
+	CompressedSize int64    `json:"compressed_size"`
+	Warnings       []string `json:"warnings"`
+}
+
+// Inspect walks the same tree as Archive, and returns the files which would be
+// included in the archive with warnings about the files which are likely
+// included by mistake.
+func Inspect(dir string, opts Options) (*Report, error) {
+	basePath, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	dirs := map[string]int64{}
+
+	if err := walk(basePath, opts.IgnoreDirectories, opts.Ignore, func(file string, relativePath string, fi os.FileInfo) error {
+		if relativePath == "." {
+			return nil
+		}
+		if fi.IsDir() {
+			if _, ok := dirs[relativePath]; !ok {
+				dirs[relativePath] = 0
+			}
+			if warning := checkDirectory(relativePath); warning != "" {
+				report.Warnings = append(report.Warnings, warning)
+			}
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		report.Files = append(report.Files, Entry{Path: relativePath, Size: fi.Size()})
+		report.Size += fi.Size()
+		for parent := path.Dir(relativePath); parent != "."; parent = path.Dir(parent) {
+			dirs[parent] += fi.Size()
+		}
+
+		warning, err := checkFile(file, relativePath, fi)
+		if err != nil {
+			return err
+		}
+		if warning != "" {
+			report.Warnings = append(report.Warnings, warning)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for parent, size := range dirs {
+		report.Directories = append(report.Directories, Entry{Path: parent, Size: size, IsDir: true})
+	}
+	slices.SortFunc(report.Directories, func(a, b Entry) int { return strings.Compare(a.Path, b.Path) })
+
+	// The compressed size can only be known by compressing the files
+	report.CompressedSize, _, err = Stat(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Largest returns the n largest entries, files and directories, by decreasing size.
+func (r *Report) Largest(n int) []Entry {
+	entries := slices.Concat(r.Files, r.Directories)
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		switch {
+		case a.Size > b.Size:
+			return -1
+		case a.Size < b.Size:
+			return 1
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return entries[:min(n, len(entries))]
+}
+
+// envExampleSuffixes are the suffixes of dotenv files which are usually
+// committed on purpose, like .env.example.
+var envExampleSuffixes = []string{".example", ".sample", ".template", ".dist", ".defaults"}
+
+// secretFilePatterns are the file names which usually contain credentials.
+var secretFilePatterns = []string{
+	"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+	"*.pem", "*.key", "*.p12", "*.pfx", "*.jks", "*.keystore",
+	".netrc", ".npmrc", ".pypirc", ".htpasswd", ".pgpass",
+	"credentials", "credentials.json", "service-account*.json", "*.tfstate",
+}
+
+func checkDirectory(relativePath string) string {
+	// Only report the top-most node_modules directory
+	if path.Base(relativePath) == "node_modules" && !strings.Contains(path.Dir(relativePath)+"/", "node_modules/") {
+		return fmt.Sprintf("%s is included. Dependencies are usually installed during the build, add it to .koyebignore to exclude it.", relativePath)
+	}
+	return ""
+}
+
+func checkFile(file string, relativePath string, fi os.FileInfo) (string, error) {
+	name := path.Base(relativePath)
+
+	if name == ".env" || (strings.HasPrefix(name, ".env.") && !slices.ContainsFunc(envExampleSuffixes, func(suffix string) bool {
+		return strings.HasSuffix(name, suffix)
+	})) {
+		return fmt.Sprintf("%s is included. It might contain secrets: use Koyeb secrets or --env-file instead.", relativePath), nil
+	}
+
+	for _, pattern := range secretFilePatterns {
+		if match, _ := path.Match(pattern, name); match {
+			return fmt.Sprintf("%s is included. Its name suggests it contains credentials.", relativePath), nil
+		}
+	}
+
+	if fi.Size() >= LargeBinarySize {
+		binary, err := isBinary(file)
+		if err != nil {
+			return "", err
+		}
+		if binary {
+			return fmt.Sprintf("%s is a large binary file (%d MB). Make sure it is needed to build or run your application.", relativePath, fi.Size()/1024/1024), nil
+		}
+	}
+	return "", nil
+}
+
+// isBinary returns true if the beginning of the file contains a NUL byte, which
+// is the heuristic used by git.
+func isBinary(file string) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, fmt.Errorf("unable to open file '%s': %w", file, err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, 8000)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, fmt.Errorf("unable to read file '%s': %w", file, err)
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
@@ -0,0 +1,49 @@
+package archive
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		".env":                    "SECRET=value",
+		".env.example":            "SECRET=",
+		".koyebignore":            "*.log\n",
+		"server.pem":              "key",
+		"debug.log":               "ignored",
+		"src/main.go":             "package main",
+		"src/node_modules/a/a.js": "a",
+		".git/HEAD":               "ref: refs/heads/main",
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	ignore, _, err := LoadIgnoreFile(dir)
+	assert.NoError(t, err)
+
+	report, err := Inspect(dir, Options{IgnoreDirectories: []string{".git"}, Ignore: ignore, CompressionLevel: gzip.DefaultCompression})
+	assert.NoError(t, err)
+
+	paths := []string{}
+	for _, file := range report.Files {
+		paths = append(paths, file.Path)
+	}
+	assert.Equal(t, []string{".env", ".env.example", ".koyebignore", "server.pem", "src/main.go", "src/node_modules/a/a.js"}, paths)
+	assert.Equal(t, int64(41), report.Size)
+	assert.Greater(t, report.CompressedSize, int64(0))
+	assert.Equal(t, Entry{Path: "src", Size: 13, IsDir: true}, report.Largest(1)[0])
+
+	assert.Len(t, report.Warnings, 3)
+	assert.True(t, strings.HasPrefix(report.Warnings[0], ".env "))
+	assert.True(t, strings.HasPrefix(report.Warnings[1], "server.pem "))
+	assert.True(t, strings.HasPrefix(report.Warnings[2], "src/node_modules "))
+}
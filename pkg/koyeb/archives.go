@@ -1,6 +1,10 @@
 package koyeb
 
 import (
+	"compress/gzip"
+	"fmt"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/spf13/pflag"
@@ -24,20 +28,29 @@ func NewArchiveCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if GetBoolFlags(cmd, "list") {
+				return h.ListArchive(ctx, args[0])
+			}
 			return h.Create(ctx, cmd, args[0])
 		}),
 	}
 	h.addFlags(createArchiveCmd.Flags())
+	createArchiveCmd.Flags().Bool("list", false, "Show the files which would be included in the archive, the largest files and directories and the compressed size, without uploading the archive")
 	archiveCmd.AddCommand(createArchiveCmd)
 	return archiveCmd
 }
 
 func NewArchiveHandler() *ArchiveHandler {
-	return &ArchiveHandler{}
+	return &ArchiveHandler{compressionLevel: gzip.DefaultCompression}
 }
 
 type ArchiveHandler struct {
 	ignoreDirectories []string
+	// noCache disables the reuse of a previously uploaded archive with the same
+	// content, and of the cached size and hash of the archive of the directory
+	noCache bool
+	// compressionLevel is the gzip compression level
+	compressionLevel int
 }
 
 // Add the flags for Archive sources
@@ -47,8 +60,12 @@ func (a *ArchiveHandler) addFlags(flags *pflag.FlagSet) {
 		[]string{".git", "node_modules", "vendor"},
 		"Set directories to ignore when building the archive.\n"+
 			"To ignore multiple directories, use the flag multiple times.\n"+
-			"To include all directories, set the flag to an empty string.",
+			"To include all directories, set the flag to an empty string.\n"+
+			"Files and directories matching the patterns of .koyebignore, or .dockerignore or .gitignore if it does not exist, are also ignored.\n"+
+			"The patterns of .dockerignore follow the rules of Docker, where all the patterns are relative to the root: use **/*.log to match the files at any level. A negation pattern can include again a file of an ignored directory, for example node_modules then !node_modules/keep.",
 	)
+	flags.Bool("no-cache", false, "Always upload a new archive, even if an archive of the same files has recently been uploaded")
+	flags.Int("compression-level", archiveDefaultCompressionLevel, "Gzip compression level of the archive, from 1 (fastest) to 9 (smallest archive), or 0 to disable compression")
 }
 
 func (a *ArchiveHandler) ParseFlags(ctx *CLIContext, flags *pflag.FlagSet) error {
@@ -57,6 +74,25 @@ func (a *ArchiveHandler) ParseFlags(ctx *CLIContext, flags *pflag.FlagSet) error
 		return err
 	}
 	a.ParseIgnoreDirectories(ignoreDirectories)
+	a.noCache, _ = flags.GetBool("no-cache")
+	level, _ := flags.GetInt("compression-level")
+	return a.ParseCompressionLevel(level)
+}
+
+// archiveDefaultCompressionLevel is the default level of compress/gzip
+const archiveDefaultCompressionLevel = 6
+
+func (a *ArchiveHandler) ParseCompressionLevel(level int) error {
+	if level < gzip.NoCompression || level > gzip.BestCompression {
+		return &errors.CLIError{
+			What:       "Error while parsing the compression level",
+			Why:        fmt.Sprintf("the compression level %d is invalid", level),
+			Additional: []string{"Archives are compressed with gzip. Other compression algorithms are not supported."},
+			Orig:       nil,
+			Solution:   "Set a compression level between 0 (no compression) and 9 (smallest archive)",
+		}
+	}
+	a.compressionLevel = level
 	return nil
 }
 
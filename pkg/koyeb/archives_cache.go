@@ -0,0 +1,151 @@
+package koyeb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// archiveCacheTTL is the duration during which an uploaded archive is reused.
+// Archives which are not used by a deployment are eventually deleted by Koyeb,
+// and there is no API to check if an archive still exists, so entries are
+// only kept for a limited time.
+const archiveCacheTTL = 24 * time.Hour
+
+// archiveCache maps the hash of an archive to the ID of the archive uploaded
+// with this content, and the fingerprint of a directory to the size and the
+// hash of its archive. It is stored in the user cache directory, usually
+// ~/.cache/koyeb/archives.json.
+type archiveCache struct {
+	path    string
+	Entries map[string]archiveCacheEntry `json:"entries"`
+	Stats   map[string]archiveStat       `json:"stats"`
+}
+
+type archiveCacheEntry struct {
+	ID        string    `json:"id"`
+	Size      string    `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// archiveStat is the size and the hash of the archive of a directory, whose
+// files have not changed since the directory has been archived.
+type archiveStat struct {
+	Size      int64     `json:"size"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// loadArchiveCache reads the cache. Errors are not fatal: in the worst case,
+// the archive is uploaded again.
+func loadArchiveCache() *archiveCache {
+	cache := &archiveCache{Entries: map[string]archiveCacheEntry{}, Stats: map[string]archiveStat{}}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		log.Debugf("Unable to find the user cache directory, the archive cache is disabled: %v", err)
+		return cache
+	}
+	cache.path = filepath.Join(dir, "koyeb", "archives.json")
+
+	content, err := os.ReadFile(cache.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debugf("Unable to read the archive cache %s: %v", cache.path, err)
+		}
+		return cache
+	}
+	if err := json.Unmarshal(content, cache); err != nil || cache.Entries == nil {
+		log.Debugf("Unable to parse the archive cache %s, ignoring it: %v", cache.path, err)
+		cache.Entries = map[string]archiveCacheEntry{}
+	}
+	if cache.Stats == nil {
+		cache.Stats = map[string]archiveStat{}
+	}
+	return cache
+}
+
+// archiveCacheKey returns the key of an archive in the cache. Archives belong
+// to an organization, so the key includes the organization, or a hash of the
+// token if the organization is not set.
+func archiveCacheKey(ctx *CLIContext, hash string) string {
+	scope := ctx.Organization
+	if scope == "" {
+		sum := sha256.Sum256([]byte(ctx.Token))
+		scope = hex.EncodeToString(sum[:8])
+	}
+	return scope + ":" + hash
+}
+
+// Get returns the archive uploaded with the given key, if it has not expired.
+func (c *archiveCache) Get(key string) (archiveCacheEntry, bool) {
+	entry, ok := c.Entries[key]
+	if !ok || time.Since(entry.CreatedAt) > archiveCacheTTL {
+		return archiveCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Set adds an entry, removes the expired entries and writes the cache.
+func (c *archiveCache) Set(key string, entry archiveCacheEntry) {
+	c.Entries[key] = entry
+	c.save()
+}
+
+// GetStat returns the size and the hash of the archive of the directory with
+// the given fingerprint, if it has not expired.
+func (c *archiveCache) GetStat(fingerprint string) (archiveStat, bool) {
+	stat, ok := c.Stats[fingerprint]
+	if !ok || time.Since(stat.CreatedAt) > archiveCacheTTL {
+		return archiveStat{}, false
+	}
+	return stat, true
+}
+
+// SetStat adds the size and the hash of the archive of the directory with the
+// given fingerprint, removes the expired entries and writes the cache.
+func (c *archiveCache) SetStat(fingerprint string, stat archiveStat) {
+	c.Stats[fingerprint] = stat
+	c.save()
+}
+
+// DeleteStat removes the size and the hash of the archive of the directory
+// with the given fingerprint, and writes the cache.
+func (c *archiveCache) DeleteStat(fingerprint string) {
+	delete(c.Stats, fingerprint)
+	c.save()
+}
+
+func (c *archiveCache) save() {
+	if c.path == "" {
+		return
+	}
+	for key, entry := range c.Entries {
+		if time.Since(entry.CreatedAt) > archiveCacheTTL {
+			delete(c.Entries, key)
+		}
+	}
+	for key, stat := range c.Stats {
+		if time.Since(stat.CreatedAt) > archiveCacheTTL {
+			delete(c.Stats, key)
+		}
+	}
+
+	content, err := json.Marshal(c)
+	if err != nil {
+		log.Debugf("Unable to serialize the archive cache: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		log.Debugf("Unable to create the archive cache directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(c.path, content, 0600); err != nil {
+		log.Debugf("Unable to write the archive cache %s: %v", c.path, err)
+	}
+}
@@ -0,0 +1,39 @@
+package koyeb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/archive"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatArchiveCache(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.js"), []byte("console.log(1)"), 0644))
+	cache := &archiveCache{Entries: map[string]archiveCacheEntry{}, Stats: map[string]archiveStat{}}
+	h := &ArchiveHandler{}
+
+	size, hash, fingerprint, err := h.statArchive(dir, archive.Options{}, cache)
+	assert.NoError(t, err)
+	expectedSize, expectedHash, err := archive.Stat(dir, archive.Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSize, size)
+	assert.Equal(t, expectedHash, hash)
+	assert.Equal(t, archiveStat{Size: size, Hash: hash, CreatedAt: cache.Stats[fingerprint].CreatedAt}, cache.Stats[fingerprint])
+
+	// The directory is not archived again while its files do not change
+	cache.Stats[fingerprint] = archiveStat{Size: 1, Hash: "cached", CreatedAt: cache.Stats[fingerprint].CreatedAt}
+	size, hash, _, err = h.statArchive(dir, archive.Options{}, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+	assert.Equal(t, "cached", hash)
+
+	// Unless --no-cache is set
+	h.noCache = true
+	size, hash, _, err = h.statArchive(dir, archive.Options{}, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSize, size)
+	assert.Equal(t, expectedHash, hash)
+}
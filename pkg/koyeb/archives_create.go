@@ -1,10 +1,14 @@
 package koyeb
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
 	"github.com/koyeb/koyeb-cli/pkg/koyeb/archive"
@@ -15,9 +19,26 @@ import (
 
 // Create makes a .tar.gz out of the given path, queries the endpoint
 // /v1/archives to get a signed URL to upload the archive to, and uploads the
-// archive.
+// archive. The files matched by the patterns of .koyebignore, or .dockerignore
+// or .gitignore if it does not exist, are not included in the archive.
+//
+// If an archive with the same content has recently been uploaded, it is reused
+// and nothing is uploaded, unless --no-cache is set.
+//
+// The archive is never stored on disk. The signed URL requires the size of the
+// archive, so the directory is archived a first time to compute the size and
+// the hash of the archive, and a second time while uploading it. Archives are
+// reproducible, so both archives are identical unless the files change in the
+// meantime. The size and the hash are cached with the fingerprint of the
+// directory: if its files have not changed, the first pass is skipped.
 func (h *ArchiveHandler) CreateArchive(ctx *CLIContext, path string) (*koyeb.CreateArchiveReply, error) {
-	tarball, err := archive.Archive(path, h.ignoreDirectories)
+	opts, err := h.archiveOptions(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := loadArchiveCache()
+	size, hash, fingerprint, err := h.statArchive(path, opts, cache)
 	if err != nil {
 		return nil, &errors.CLIError{
 			What:       "Unable to create archive",
@@ -27,17 +48,17 @@ func (h *ArchiveHandler) CreateArchive(ctx *CLIContext, path string) (*koyeb.Cre
 			Solution:   errors.SolutionFixRequest,
 		}
 	}
-	defer tarball.Close()
 
-	stat, err := tarball.File.Stat()
-	if err != nil {
-		return nil, &errors.CLIError{
-			What:       "Unable to create archive",
-			Why:        fmt.Sprintf("error while getting the file info of `%s` (archive of %s)", tarball.File.Name(), path),
-			Additional: nil,
-			Orig:       err,
-			Solution:   errors.SolutionUpdateOrIssue,
-		}
+	cacheKey := archiveCacheKey(ctx, hash)
+	if entry, ok := cache.Get(cacheKey); ok && !h.noCache {
+		log.Infof("The content of `%s` has not changed since the upload of the archive %s, reusing it", path, entry.ID)
+		createdAt := entry.CreatedAt
+		return &koyeb.CreateArchiveReply{Archive: &koyeb.Archive{
+			Id:             koyeb.PtrString(entry.ID),
+			OrganizationId: koyeb.PtrString(ctx.Organization),
+			Size:           koyeb.PtrString(entry.Size),
+			CreatedAt:      &createdAt,
+		}}, nil
 	}
 
 	// Request the signed upload URL
@@ -45,23 +66,78 @@ func (h *ArchiveHandler) CreateArchive(ctx *CLIContext, path string) (*koyeb.Cre
 	// The cast to string is necessary because the API expects a string. This is
 	// because the underlying type to store the size is uint64, which is not
 	// representable in JSON.
-	c.SetSize(fmt.Sprintf("%d", stat.Size()))
+	c.SetSize(fmt.Sprintf("%d", size))
 
 	res, resp, err := ctx.Client.ArchivesApi.CreateArchive(ctx.Context).Archive(*c).Execute()
 	if err != nil {
 		return nil, errors.NewCLIErrorFromAPIError(
-			fmt.Sprintf("Error while requesting an upload URL to upload the archive '%s' (%d bytes)", path, stat.Size()),
+			fmt.Sprintf("Error while requesting an upload URL to upload the archive '%s' (%d bytes)", path, size),
 			err,
 			resp,
 		)
 	}
 	// Upload the archive to the upload URL
-	if err := h.uploadArchive(tarball.File, stat.Size(), *res.GetArchive().UploadUrl); err != nil {
+	if err := h.uploadArchive(path, opts, size, hash, *res.GetArchive().UploadUrl); err != nil {
+		// The files might have changed without changing the fingerprint, for
+		// example if the modification time has been restored
+		cache.DeleteStat(fingerprint)
 		return nil, err
 	}
+	uploaded := res.GetArchive()
+	cache.Set(cacheKey, archiveCacheEntry{
+		ID:        uploaded.GetId(),
+		Size:      uploaded.GetSize(),
+		CreatedAt: time.Now(),
+	})
 	return res, nil
 }
 
+// statArchive returns the size and the hash of the archive of path, and the
+// fingerprint of the directory. The size and the hash are read from the cache
+// if the files have not changed since the directory has been archived, unless
+// --no-cache is set.
+func (h *ArchiveHandler) statArchive(path string, opts archive.Options, cache *archiveCache) (int64, string, string, error) {
+	fingerprint, err := archive.Fingerprint(path, opts)
+	if err != nil {
+		return 0, "", "", err
+	}
+	if stat, ok := cache.GetStat(fingerprint); ok && !h.noCache {
+		log.Debugf("The files of `%s` have not changed, reusing the size and the hash of its archive", path)
+		return stat.Size, stat.Hash, fingerprint, nil
+	}
+
+	size, hash, err := archive.Stat(path, opts)
+	if err != nil {
+		return 0, "", "", err
+	}
+	cache.SetStat(fingerprint, archiveStat{Size: size, Hash: hash, CreatedAt: time.Now()})
+	return size, hash, fingerprint, nil
+}
+
+// archiveOptions reads the patterns of the files to exclude from the archive
+// of path, and returns the options to archive it.
+func (h *ArchiveHandler) archiveOptions(path string) (archive.Options, error) {
+	ignore, ignoreFile, err := archive.LoadIgnoreFile(path)
+	if err != nil {
+		return archive.Options{}, &errors.CLIError{
+			What:       "Unable to create archive",
+			Why:        fmt.Sprintf("we encountered an error while reading the ignore file of the directory `%s`", path),
+			Additional: []string{fmt.Sprintf("The files matching the patterns of %s are not included in the archive.", strings.Join(archive.IgnoreFiles, ", "))},
+			Orig:       err,
+			Solution:   errors.SolutionFixRequest,
+		}
+	}
+	if ignoreFile != "" {
+		log.Infof("Excluding the files matching the patterns of %s from the archive", ignoreFile)
+	}
+
+	return archive.Options{
+		IgnoreDirectories: h.ignoreDirectories,
+		Ignore:            ignore,
+		CompressionLevel:  h.compressionLevel,
+	}, nil
+}
+
 func (h *ArchiveHandler) Create(ctx *CLIContext, cmd *cobra.Command, path string) error {
 	res, err := h.CreateArchive(ctx, path)
 	if err != nil {
@@ -73,34 +149,60 @@ func (h *ArchiveHandler) Create(ctx *CLIContext, cmd *cobra.Command, path string
 	return nil
 }
 
-func (h *ArchiveHandler) uploadArchive(file *os.File, size int64, url string) error {
-	log.Debugf("Start uploading archive %s to %s (%d bytes)", file.Name(), url, size)
+// errArchiveChanged is returned when the archive streamed to the upload URL is
+// different from the archive whose size and hash were sent to the API.
+var errArchiveChanged = fmt.Errorf("the files changed while the archive was being uploaded")
+
+// uploadArchive archives path and streams the archive to the upload URL.
+func (h *ArchiveHandler) uploadArchive(path string, opts archive.Options, size int64, hash string, url string) error {
+	log.Debugf("Start uploading archive of %s to %s (%d bytes)", path, url, size)
 
 	client := http.Client{}
 
-	// Reset the file pointer to the beginning, otherwise the file will be empty
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		log.Debugf("Unable to seek to the beginning of the file: %v", err)
-	}
+	reader, writer := io.Pipe()
+	go func() {
+		checksum := sha256.New()
+		err := archive.Write(io.MultiWriter(writer, checksum), path, opts)
+		if err == nil && hex.EncodeToString(checksum.Sum(nil)) != hash {
+			err = errArchiveChanged
+		}
+		writer.CloseWithError(err)
+	}()
+	// Unblock the goroutine if the request fails before the archive has been fully read
+	defer reader.Close()
 
-	req, err := http.NewRequest("PUT", url, file)
+	progress := newProgressBar("Uploading archive", size)
+	req, err := http.NewRequest("PUT", url, progress.Reader(reader))
 	if err != nil {
 		log.Debugf("Error while creating the request: %v", err)
 		return err
 	}
+	// Without ContentLength, the request would use chunked encoding which is
+	// not accepted by the signed URL
+	req.ContentLength = size
 
 	req.Header.Set("Content-Type", "application/octet-stream")
 	req.Header.Set("x-goog-content-length-range", fmt.Sprintf("%d,%d", size, size))
 
 	resp, err := client.Do(req)
+	progress.Finish()
 	if err != nil {
+		if stderrors.Is(err, errArchiveChanged) {
+			return &errors.CLIError{
+				What:       "Error while uploading archive",
+				Why:        fmt.Sprintf("the content of `%s` changed during the upload", path),
+				Additional: []string{"The archive is created while it is uploaded, and its size must be known in advance. The files of the directory must not be modified during the upload."},
+				Orig:       err,
+				Solution:   errors.SolutionTryAgainOrUpdateOrIssue,
+			}
+		}
 		return &errors.CLIError{
 			What: "Error while uploading archive",
-			Why:  fmt.Sprintf("Failed to upload the archive %s", file.Name()),
+			Why:  fmt.Sprintf("Failed to upload the archive of %s", path),
 			Additional: []string{
 				"An error occurred while uploading the archive",
 			},
-			Orig:     nil,
+			Orig:     err,
 			Solution: "Make sure that your network connection is stable. If the problem persists, try to update the CLI to the latest version.",
 		}
 	}
@@ -108,7 +210,7 @@ func (h *ArchiveHandler) uploadArchive(file *os.File, size int64, url string) er
 
 	if resp.StatusCode != http.StatusOK {
 		body, readAllErr := io.ReadAll(resp.Body)
-		if err != nil {
+		if readAllErr != nil {
 			log.Debugf("Unable to read response body: %v", readAllErr)
 		}
 
@@ -118,7 +220,7 @@ func (h *ArchiveHandler) uploadArchive(file *os.File, size int64, url string) er
 
 		return &errors.CLIError{
 			What: "Error while uploading archive",
-			Why:  fmt.Sprintf("Failed to upload the archive %s", file.Name()),
+			Why:  fmt.Sprintf("Failed to upload the archive of %s", path),
 			Additional: []string{
 				fmt.Sprintf("We tried to upload the archive to our archive storage but the server returned a HTTP/%d response", resp.StatusCode),
 			},
@@ -127,7 +229,7 @@ func (h *ArchiveHandler) uploadArchive(file *os.File, size int64, url string) er
 		}
 	}
 
-	log.Debugf("Finished uploading archive %s to %s", file.Name(), url)
+	log.Debugf("Finished uploading archive of %s to %s", path, url)
 	return nil
 }
 
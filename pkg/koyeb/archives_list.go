@@ -0,0 +1,116 @@
+package koyeb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/archive"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/renderer"
+	log "github.com/sirupsen/logrus"
+)
+
+// archiveLargestEntries is the number of files and directories displayed in the
+// "Largest files and directories" table.
+const archiveLargestEntries = 10
+
+// ListArchive displays the files which would be included in the archive of
+// path, without uploading it.
+func (h *ArchiveHandler) ListArchive(ctx *CLIContext, path string) error {
+	opts, err := h.archiveOptions(path)
+	if err != nil {
+		return err
+	}
+
+	report, err := archive.Inspect(path, opts)
+	if err != nil {
+		return &errors.CLIError{
+			What:       "Unable to create archive",
+			Why:        fmt.Sprintf("we encountered an error while archiving the directory `%s`", path),
+			Additional: nil,
+			Orig:       err,
+			Solution:   errors.SolutionFixRequest,
+		}
+	}
+
+	renderer.NewChainRenderer(ctx.Renderer).
+		Render(&ArchiveFilesReply{title: "Files", entries: report.Files}).
+		Render(&ArchiveFilesReply{title: "Largest files and directories", entries: report.Largest(archiveLargestEntries)}).
+		Render(&ArchiveSummaryReply{report})
+
+	for _, warning := range report.Warnings {
+		log.Warn(warning)
+	}
+	return nil
+}
+
+// formatBytes formats a size in bytes using binary units, e.g. 1.5 MiB.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+type ArchiveFilesReply struct {
+	title   string
+	entries []archive.Entry
+}
+
+func (r *ArchiveFilesReply) Title() string {
+	return r.title
+}
+
+func (r *ArchiveFilesReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.entries)
+}
+
+func (r *ArchiveFilesReply) Headers() []string {
+	return []string{"path", "size"}
+}
+
+func (r *ArchiveFilesReply) Fields() []map[string]string {
+	resp := []map[string]string{}
+	for _, entry := range r.entries {
+		path := entry.Path
+		if entry.IsDir {
+			path += "/"
+		}
+		resp = append(resp, map[string]string{
+			"path": path,
+			"size": formatBytes(entry.Size),
+		})
+	}
+	return resp
+}
+
+type ArchiveSummaryReply struct {
+	report *archive.Report
+}
+
+func (ArchiveSummaryReply) Title() string {
+	return "Archive"
+}
+
+func (r *ArchiveSummaryReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.report)
+}
+
+func (r *ArchiveSummaryReply) Headers() []string {
+	return []string{"files", "size", "compressed_size", "warnings"}
+}
+
+func (r *ArchiveSummaryReply) Fields() []map[string]string {
+	return []map[string]string{{
+		"files":           fmt.Sprintf("%d", len(r.report.Files)),
+		"size":            formatBytes(r.report.Size),
+		"compressed_size": formatBytes(r.report.CompressedSize),
+		"warnings":        fmt.Sprintf("%d", len(r.report.Warnings)),
+	}}
+}
@@ -1,9 +1,15 @@
 package koyeb
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var completionCmd = &cobra.Command{
@@ -57,3 +63,167 @@ $ koyeb completion fish > ~/.config/fish/completions/koyeb.fish
 		return nil
 	},
 }
+
+// resourceCompleter returns the values suggested for an argument or a flag.
+type resourceCompleter func(ctx *CLIContext, cmd *cobra.Command) ([]string, error)
+
+// completeArgs returns a cobra ValidArgsFunction which completes the
+// positional arguments of a command: the argument at the index i is completed
+// by completers[i], and is not completed if completers[i] is nil.
+func completeArgs(completers ...resourceCompleter) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= len(completers) || completers[len(args)] == nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return complete(cmd, completers[len(args)])
+	}
+}
+
+// completeFlag returns a cobra flag completion function.
+func completeFlag(completer resourceCompleter) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return complete(cmd, completer)
+	}
+}
+
+func complete(cmd *cobra.Command, completer resourceCompleter) ([]string, cobra.ShellCompDirective) {
+	// The context is set up by the root command before running the completion
+	// request. It is missing if the configuration could not be loaded.
+	if cmd.Context() == nil || cmd.Context().Value(ctx_mapper) == nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	values, err := completer(GetCLIContext(cmd.Context()), cmd)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return values, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeApps(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.App().Identifiers()
+}
+
+// completeServices suggests <app>/<service>, or only the service names of the
+// application set with --app.
+func completeServices(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	services, err := ctx.Mapper.Service().Identifiers()
+	if err != nil {
+		return nil, err
+	}
+	app := ""
+	if cmd.Flags().Lookup("app") != nil {
+		app, _ = cmd.Flags().GetString("app")
+	}
+	if app == "" {
+		return services, nil
+	}
+	ret := []string{}
+	for _, service := range services {
+		if name, ok := strings.CutPrefix(service, app+"/"); ok {
+			ret = append(ret, name)
+		}
+	}
+	return ret, nil
+}
+
+func completeInstances(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.Instance().Identifiers()
+}
+
+func completeDeployments(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.Deployment().Identifiers()
+}
+
+func completeRegionalDeployments(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.RegionalDeployment().Identifiers()
+}
+
+func completeSecrets(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.Secret().Identifiers()
+}
+
+func completeVolumes(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.Volume().Identifiers()
+}
+
+func completeSnapshots(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.Snapshot().Identifiers()
+}
+
+func completeDomains(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.Domain().Identifiers()
+}
+
+func completeDatabases(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	return ctx.Mapper.Database().Identifiers()
+}
+
+// completeRegions suggests the regions of the catalog, with their name as
+// description.
+func completeRegions(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	res, resp, err := ctx.Client.CatalogRegionsApi.ListRegions(ctx.Context).Limit("100").Execute()
+	if err != nil {
+		return nil, errors.NewCLIErrorFromAPIError("Error while listing the regions", err, resp)
+	}
+	ret := []string{}
+	for _, region := range res.GetRegions() {
+		ret = append(ret, fmt.Sprintf("%s\t%s", region.GetId(), region.GetName()))
+	}
+	return ret, nil
+}
+
+// completeInstanceTypes suggests the instance types of the catalog, with their
+// resources as description.
+func completeInstanceTypes(ctx *CLIContext, cmd *cobra.Command) ([]string, error) {
+	res, resp, err := ctx.Client.CatalogInstancesApi.ListCatalogInstances(ctx.Context).Limit("100").Execute()
+	if err != nil {
+		return nil, errors.NewCLIErrorFromAPIError("Error while listing the instance types", err, resp)
+	}
+	ret := []string{}
+	for _, instance := range res.GetInstances() {
+		ret = append(ret, fmt.Sprintf("%s\t%s vCPU, %s RAM, %s disk", instance.GetId(), formatVCPU(instance), instance.GetMemory(), instance.GetDisk()))
+	}
+	return ret, nil
+}
+
+func formatVCPU(instance koyeb.CatalogInstanceListItem) string {
+	if shares := instance.GetVcpuShares(); shares > 0 {
+		return strconv.FormatFloat(float64(shares), 'f', -1, 32)
+	}
+	return strconv.FormatInt(instance.GetVcpu(), 10)
+}
+
+var (
+	completeServiceTypes = cobra.FixedCompletions([]string{"web", "worker", "sandbox"}, cobra.ShellCompDirectiveNoFileComp)
+	completeLogTypes     = cobra.FixedCompletions([]string{"runtime", "build"}, cobra.ShellCompDirectiveNoFileComp)
+	completeSecretTypes  = cobra.FixedCompletions(SecretTypeAllValues(), cobra.ShellCompDirectiveNoFileComp)
+	// Databases do not use the instance types of the catalog
+	completeDatabaseInstanceTypes = cobra.FixedCompletions([]string{"free", "small", "medium", "large"}, cobra.ShellCompDirectiveNoFileComp)
+)
+
+// registerFlagCompletions registers the completion of the flags shared by many
+// commands, like --app, --region or --instance-type. Flags which already have
+// a completion function, like --instance-type of koyeb database create, are
+// left untouched.
+func registerFlagCompletions(cmd *cobra.Command) {
+	// The keys are the type and the name of the flags, as --service of koyeb
+	// deploy is not a service name
+	completers := map[string]resourceCompleter{
+		"string/app":           completeApps,
+		"string/service":       completeServices,
+		"string/instance":      completeInstances,
+		"string/region":        completeRegions,
+		"stringSlice/regions":  completeRegions,
+		"string/instance-type": completeInstanceTypes,
+	}
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if completer, ok := completers[flag.Value.Type()+"/"+flag.Name]; ok {
+			// An error is returned when the flag already has a completion function
+			cmd.RegisterFlagCompletionFunc(flag.Name, completeFlag(completer)) //nolint:errcheck
+		}
+	})
+	for _, subcmd := range cmd.Commands() {
+		registerFlagCompletions(subcmd)
+	}
+}
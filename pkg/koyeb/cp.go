@@ -1,120 +1,471 @@
 package koyeb
 
 import (
+	"archive/tar"
 	"bytes"
+	"context"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/archive"
 	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
 )
 
+// copyResumeAttempts is the number of times the copy of a single file is
+// resumed after the connection to the instance is lost.
+const copyResumeAttempts = 5
+
+// copyResumeDelay is the delay before resuming the copy of a single file.
+const copyResumeDelay = 2 * time.Second
+
+type CopyOptions struct {
+	// Compress the transfer with gzip
+	Compress bool
+	// Exclude the files and directories matching these patterns, which use the .gitignore syntax
+	Exclude []string
+	// PreservePermissions keeps the owner and the exact permissions of the files copied
+	PreservePermissions bool
+}
+
 type CopyManager struct {
-	Src *FileSpec
-	Dst *FileSpec
+	Src     *FileSpec
+	Dst     *FileSpec
+	Options CopyOptions
+
+	client *ExecAPIClient
 }
 
-func NewCopyManager(src, dst *FileSpec) (*CopyManager, error) {
+func NewCopyManager(src, dst *FileSpec, opts CopyOptions) (*CopyManager, error) {
 	if err := ValidateTargets(src, dst); err != nil {
 		return nil, err
 	}
 
 	return &CopyManager{
-		Src: src,
-		Dst: dst,
+		Src:     src,
+		Dst:     dst,
+		Options: opts,
 	}, nil
 }
 
 func (manager *CopyManager) Copy(ctx *CLIContext) error {
 	isCopyToInstance := len(manager.Src.InstanceID) == 0
+	manager.client = ctx.ExecClient
 
-	if err := manager.Src.Validate(ctx, isCopyToInstance); err != nil {
-		return err
+	exclude, err := archive.NewIgnoreMatcher(strings.NewReader(strings.Join(manager.Options.Exclude, "\n")))
+	if err != nil {
+		return &errors.CLIError{
+			What:       "Error while copying",
+			Why:        "unable to parse the --exclude patterns",
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Fix the --exclude patterns and try again",
+		}
+	}
+	opts := tarOptions{
+		compress:            manager.Options.Compress,
+		preservePermissions: manager.Options.PreservePermissions,
+		exclude:             archive.Options{Ignore: exclude},
+	}
+
+	// Remote glob patterns are validated when they are expanded
+	if isCopyToInstance || !hasGlob(manager.Src.FilePath) {
+		if err := manager.Src.Validate(ctx, isCopyToInstance); err != nil {
+			return err
+		}
 	}
 
 	if err := manager.Dst.Validate(ctx, isCopyToInstance); err != nil {
 		return err
 	}
 
+	// Stop resuming the copy when the user presses Ctrl+C
+	execCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	if isCopyToInstance {
-		return manager.copyToInstance(ctx)
+		return manager.copyToInstance(execCtx, opts)
 	}
-	return manager.copyFromInstance(ctx)
+	return manager.copyFromInstance(execCtx, opts)
 }
 
-func (manager *CopyManager) copyToInstance(ctx *CLIContext) error {
+func (manager *CopyManager) copyToInstance(ctx context.Context, opts tarOptions) error {
+	info, err := os.Stat(manager.Src.FilePath)
+	if err != nil {
+		return newCopyError(manager.Src.FilePath, err)
+	}
+	// Single files are copied without tar, so the copy can be resumed
+	if info.Mode().IsRegular() && !opts.compress {
+		return manager.uploadFile(ctx, info)
+	}
+
+	total, err := localSize(manager.Src.FilePath, opts.exclude)
+	if err != nil {
+		return newCopyError(manager.Src.FilePath, err)
+	}
+	opts.progress = newProgressBar("Copying", total)
+
 	reader, writer := io.Pipe()
+	defer reader.Close()
 
-	var tarErr error
-	go func(srcPath string, writer io.WriteCloser) {
-		defer writer.Close()
-		tarErr = Tar(srcPath, writer)
-	}(manager.Src.FilePath, writer)
+	tarErr := make(chan error, 1)
+	go func() {
+		err := Tar(manager.Src.FilePath, writer, opts)
+		writer.CloseWithError(err)
+		tarErr <- err
+	}()
 
-	retCode, err := ctx.ExecClient.ExecWithStreams(
-		ctx.Context,
-		&StdStreams{
-			Stdin:  reader,
-			Stdout: os.Stdout,
-			Stderr: os.Stderr,
-		},
-		ExecId{
-			Id:   manager.Dst.InstanceID,
-			Type: koyeb.EXECCOMMANDREQUESTIDTYPE_INSTANCE_ID,
-		},
-		[]string{"tar", "-C", manager.Dst.FilePath, "-xf", "-"},
-	)
+	command := []string{"tar", "-C", manager.Dst.FilePath, "-x", "-f", "-"}
+	if opts.compress {
+		command = append(command, "-z")
+	}
+	if opts.preservePermissions {
+		command = append(command, "-p")
+	} else {
+		// Do not restore the owner of the local files
+		command = append(command, "-o")
+	}
+	retCode, err := manager.exec(ctx, manager.Dst.InstanceID, reader, os.Stdout, command...)
+	reader.Close()
+	opts.progress.Finish()
+	if err := <-tarErr; err != nil && !stderrors.Is(err, io.ErrClosedPipe) {
+		return newCopyError(manager.Src.FilePath, err)
+	}
 	if err != nil || retCode != 0 {
+		return newCopyError(manager.Src.FilePath, fmt.Errorf("err: %v, return code: %v", err, retCode))
+	}
+
+	return nil
+}
+
+func (manager *CopyManager) copyFromInstance(ctx context.Context, opts tarOptions) error {
+	pathDir := path.Dir(manager.Src.FilePath)
+	pathBase := path.Base(manager.Src.FilePath)
+	if pathBase == "/" {
+		pathBase = "."
+	}
+
+	files, err := manager.listRemoteFiles(ctx, pathDir, pathBase, opts.exclude)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
 		return &errors.CLIError{
 			What:       "Error while copying",
-			Why:        fmt.Sprintf("Failed copying path %v", manager.Src.FilePath),
-			Additional: []string{"This might indicate issues with connectivity to the instance"},
-			Orig:       fmt.Errorf("err: %v, return code: %v, tar err: %v", err, retCode, tarErr),
-			Solution:   "Make sure that your network connection is stable. If the problem persists, try to update the CLI to the latest version.",
+			Why:        fmt.Sprintf("No file matches the remote path %s", manager.Src.FilePath),
+			Additional: []string{"Only regular files, directories and symbolic links are copied, and the files matching --exclude are skipped"},
+			Orig:       nil,
+			Solution:   "Make sure that the remote path exists",
 		}
 	}
+	// Single files are copied without tar, so the copy can be resumed
+	if len(files) == 1 && files[0].name == pathBase && files[0].isRegular() && !opts.compress {
+		return manager.downloadFile(ctx, pathDir, files[0], opts)
+	}
+
+	total := int64(0)
+	names := []string{}
+	for _, file := range files {
+		total += file.size
+		names = append(names, file.name)
+	}
+	opts.progress = newProgressBar("Copying", total)
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	untarErr := make(chan error, 1)
+	go func() {
+		err := Untar(manager.Dst.FilePath, reader, opts)
+		// Unblock the exec if the extraction failed
+		reader.CloseWithError(err)
+		untarErr <- err
+	}()
+
+	// The list of files to copy is sent on the standard input of tar. The
+	// directories are listed with their content, except the excluded files, so
+	// tar must not add their content again.
+	command := []string{"tar", "-C", pathDir, "-c", "-f", "-", "--no-recursion", "-T", "-"}
+	if opts.compress {
+		command = append(command, "-z")
+	}
+	retCode, err := manager.exec(ctx, manager.Src.InstanceID, strings.NewReader(strings.Join(names, "\n")+"\n"), writer, command...)
+	writer.Close()
+	extractErr := <-untarErr
+	opts.progress.Finish()
+	if err != nil || retCode != 0 || extractErr != nil {
+		return newCopyError(manager.Src.FilePath, fmt.Errorf("err: %v, return code: %v, untar err: %v", err, retCode, extractErr))
+	}
 
 	return nil
 }
 
-func (manager *CopyManager) copyFromInstance(ctx *CLIContext) error {
-	reader, writer := io.Pipe()
+// uploadFile copies the local file Src to the directory Dst of the instance.
+// If the connection to the instance is lost, the copy is resumed from the size
+// of the remote file.
+func (manager *CopyManager) uploadFile(ctx context.Context, info os.FileInfo) error {
+	target := path.Join(manager.Dst.FilePath, info.Name())
 
-	var untarErr error
-	go func(dstPath string, reader io.ReadCloser) {
-		defer reader.Close()
+	f, err := os.Open(manager.Src.FilePath)
+	if err != nil {
+		return newCopyError(manager.Src.FilePath, err)
+	}
+	defer f.Close()
 
-		untarErr = Untar(dstPath, reader)
-	}(manager.Dst.FilePath, reader)
+	progress := newProgressBar("Copying", info.Size())
+	offset := int64(0)
+	for attempt := 1; ; attempt++ {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return newCopyError(manager.Src.FilePath, err)
+		}
+		progress.Set(offset)
 
-	pathDir := path.Dir(manager.Src.FilePath)
-	pathBase := path.Base(manager.Src.FilePath)
-	retCode, err := ctx.ExecClient.ExecWithStreams(
-		ctx.Context,
+		script := `cat > "$1"`
+		if offset > 0 {
+			script = `cat >> "$1"`
+		}
+		retCode, err := manager.exec(ctx, manager.Dst.InstanceID, progress.Reader(f), os.Stdout, "sh", "-c", script, "sh", target)
+		if err == nil && retCode == 0 {
+			break
+		}
+		if err == nil || ctx.Err() != nil || attempt >= copyResumeAttempts {
+			progress.Finish()
+			return newCopyError(manager.Src.FilePath, fmt.Errorf("err: %v, return code: %v", err, retCode))
+		}
+
+		log.Warnf("The connection to the instance was lost, resuming the copy (attempt %d/%d)", attempt+1, copyResumeAttempts)
+		time.Sleep(copyResumeDelay)
+		offset, err = manager.remoteFileSize(ctx, manager.Dst.InstanceID, target)
+		if err != nil || offset > info.Size() {
+			offset = 0
+		}
+	}
+	progress.Finish()
+
+	if size, err := manager.remoteFileSize(ctx, manager.Dst.InstanceID, target); err != nil || size != info.Size() {
+		return newCopyError(manager.Src.FilePath, fmt.Errorf("the size of the remote file is %d bytes instead of %d: %v", size, info.Size(), err))
+	}
+
+	// The header gives the owner and the mode of the file on all the platforms
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return newCopyError(manager.Src.FilePath, err)
+	}
+	mode := header.Mode & 0777
+	if manager.Options.PreservePermissions {
+		mode = header.Mode & 07777
+		retCode, err := manager.exec(ctx, manager.Dst.InstanceID, strings.NewReader(""), os.Stdout, "chown", fmt.Sprintf("%d:%d", header.Uid, header.Gid), target)
+		if err != nil || retCode != 0 {
+			return newCopyError(manager.Src.FilePath, fmt.Errorf("unable to set the owner of %s: err: %v, return code: %v", target, err, retCode))
+		}
+	}
+	retCode, err := manager.exec(ctx, manager.Dst.InstanceID, strings.NewReader(""), os.Stdout, "chmod", fmt.Sprintf("%o", mode), target)
+	if err != nil || retCode != 0 {
+		return newCopyError(manager.Src.FilePath, fmt.Errorf("unable to set the permissions of %s: err: %v, return code: %v", target, err, retCode))
+	}
+	return nil
+}
+
+// downloadFile copies the remote file dir/file.name to Dst. If the connection
+// to the instance is lost, the copy is resumed from the size of the local file.
+func (manager *CopyManager) downloadFile(ctx context.Context, dir string, file remoteFile, opts tarOptions) error {
+	source := path.Join(dir, file.name)
+	target := manager.Dst.FilePath
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		target = filepath.Join(target, path.Base(file.name))
+	}
+
+	// The umask applies to the mode when the file is created
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(file.mode&0777))
+	if err != nil {
+		return newCopyError(source, err)
+	}
+	defer f.Close()
+
+	progress := newProgressBar("Copying", file.size)
+	for attempt := 1; ; attempt++ {
+		// Writes are sequential, so the current position is the size of the local file
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return newCopyError(source, err)
+		}
+		progress.Set(offset)
+
+		retCode, err := manager.exec(ctx, manager.Src.InstanceID, strings.NewReader(""), progress.Writer(f),
+			"sh", "-c", `tail -c +"$2" -- "$1"`, "sh", source, strconv.FormatInt(offset+1, 10))
+		if err == nil && retCode == 0 {
+			break
+		}
+		if err == nil || ctx.Err() != nil || attempt >= copyResumeAttempts {
+			progress.Finish()
+			return newCopyError(source, fmt.Errorf("err: %v, return code: %v", err, retCode))
+		}
+
+		log.Warnf("The connection to the instance was lost, resuming the copy (attempt %d/%d)", attempt+1, copyResumeAttempts)
+		time.Sleep(copyResumeDelay)
+	}
+	progress.Finish()
+
+	if size, err := f.Seek(0, io.SeekCurrent); err != nil || size != file.size {
+		return newCopyError(source, fmt.Errorf("the size of the local file is %d bytes instead of %d: %v", size, file.size, err))
+	}
+	if opts.preservePermissions {
+		if err := restoreOwnerAndMode(target, file.uid, file.gid, int64(file.mode&07777)); err != nil {
+			return newCopyError(source, err)
+		}
+	}
+	return nil
+}
+
+// remoteFile is a regular file, a directory or a symbolic link of the
+// instance, whose name is relative to the directory copied.
+type remoteFile struct {
+	name string
+	size int64
+	// mode is the raw mode returned by stat, with the type of the file
+	mode uint32
+	uid  int
+	gid  int
+}
+
+func (file remoteFile) isRegular() bool {
+	return file.mode&0170000 == 0100000
+}
+
+func (file remoteFile) isDir() bool {
+	return file.mode&0170000 == 0040000
+}
+
+// listRemoteFiles returns the regular files, directories and symbolic links of
+// the instance matching the glob pattern in dir, and the content of the
+// matching directories, except those matched by exclude.
+func (manager *CopyManager) listRemoteFiles(ctx context.Context, dir string, pattern string, exclude archive.Options) ([]remoteFile, error) {
+	var stdout bytes.Buffer
+	// With an empty IFS, the pattern is expanded but not split on spaces
+	retCode, err := manager.exec(ctx, manager.Src.InstanceID, strings.NewReader(""), &stdout,
+		"sh", "-c", `cd -- "$1" && IFS= && find $2 \( -type f -o -type d -o -type l \) -exec stat -c "%s %f %u %g %n" {} +`, "sh", dir, pattern)
+	if err != nil {
+		return nil, newCopyError(path.Join(dir, pattern), err)
+	}
+
+	ret := []remoteFile{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.SplitN(line, " ", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		size, sizeErr := strconv.ParseInt(fields[0], 10, 64)
+		mode, modeErr := strconv.ParseUint(fields[1], 16, 32)
+		uid, uidErr := strconv.Atoi(fields[2])
+		gid, gidErr := strconv.Atoi(fields[3])
+		if sizeErr != nil || modeErr != nil || uidErr != nil || gidErr != nil {
+			continue
+		}
+		file := remoteFile{name: strings.TrimPrefix(fields[4], "./"), size: size, mode: uint32(mode), uid: uid, gid: gid}
+		if file.name == "." || exclude.Excludes(file.name, file.isDir()) {
+			continue
+		}
+		// Only the content of the regular files is transferred
+		if !file.isRegular() {
+			file.size = 0
+		}
+		ret = append(ret, file)
+	}
+	if len(ret) == 0 && retCode != 0 {
+		return nil, &errors.CLIError{
+			What:       "Error while copying",
+			Why:        fmt.Sprintf("The remote path %s doesn't exist", path.Join(dir, pattern)),
+			Additional: []string{"You might also double check if you have permissions to read this path"},
+			Orig:       nil,
+			Solution:   "Make sure that the remote path exists",
+		}
+	}
+	return ret, nil
+}
+
+// remoteFileSize returns the size of a file of the instance.
+func (manager *CopyManager) remoteFileSize(ctx context.Context, instanceID string, file string) (int64, error) {
+	var stdout bytes.Buffer
+	retCode, err := manager.exec(ctx, instanceID, strings.NewReader(""), &stdout, "sh", "-c", `wc -c < "$1"`, "sh", file)
+	if err != nil {
+		return 0, err
+	}
+	if retCode != 0 {
+		return 0, fmt.Errorf("unable to get the size of %s, return code: %v", file, retCode)
+	}
+	return strconv.ParseInt(strings.TrimSpace(stdout.String()), 10, 64)
+}
+
+func (manager *CopyManager) exec(ctx context.Context, instanceID string, stdin io.Reader, stdout io.Writer, command ...string) (int, error) {
+	return execInstance(ctx, manager.client, instanceID, stdin, stdout, command...)
+}
+
+// execInstance runs a non-interactive command in the instance. The errors of
+// the command are displayed on stderr.
+func execInstance(ctx context.Context, client *ExecAPIClient, instanceID string, stdin io.Reader, stdout io.Writer, command ...string) (int, error) {
+	return client.ExecWithStreams(
+		ctx,
 		&StdStreams{
-			Stdin:  bytes.NewReader([]byte{}),
-			Stdout: writer,
+			Stdin:  stdin,
+			Stdout: stdout,
 			Stderr: os.Stderr,
 		},
 		ExecId{
-			Id:   manager.Src.InstanceID,
+			Id:   instanceID,
 			Type: koyeb.EXECCOMMANDREQUESTIDTYPE_INSTANCE_ID,
 		},
-		[]string{"tar", "-C", pathDir, "-czf", "-", pathBase},
+		command,
 	)
-	if err != nil || retCode != 0 {
-		return &errors.CLIError{
-			What:       "Error while copying",
-			Why:        fmt.Sprintf("Failed copying path %v", manager.Src.FilePath),
-			Additional: []string{"This might indicate issues with connectivity to the instance"},
-			Orig:       fmt.Errorf("err: %v, return code: %v, untar err: %v", err, retCode, untarErr),
-			Solution:   "Make sure that your network connection is stable. If the problem persists, try to update the CLI to the latest version.",
+}
+
+// localSize returns the total size of the regular files of src which are not excluded.
+func localSize(src string, exclude archive.Options) (int64, error) {
+	total := int64(0)
+	err := filepath.Walk(src, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
 		}
-	}
+		relativePath, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		if relativePath != "." && exclude.Excludes(filepath.ToSlash(relativePath), fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fi.Mode().IsRegular() {
+			total += fi.Size()
+		}
+		return nil
+	})
+	return total, err
+}
 
-	return nil
+// hasGlob returns true if the path contains a glob pattern.
+func hasGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+func newCopyError(path string, err error) *errors.CLIError {
+	return &errors.CLIError{
+		What:       "Error while copying",
+		Why:        fmt.Sprintf("Failed copying path %v", path),
+		Additional: []string{"This might indicate issues with connectivity to the instance"},
+		Orig:       err,
+		Solution:   "Make sure that your network connection is stable. If the problem persists, try to update the CLI to the latest version.",
+	}
 }
 
 func ValidateTargets(srcSpec, dstSpec *FileSpec) error {
@@ -128,6 +479,16 @@ func ValidateTargets(srcSpec, dstSpec *FileSpec) error {
 		}
 	}
 
+	if dstSpec.InstanceID != "" && hasGlob(dstSpec.FilePath) || srcSpec.InstanceID != "" && hasGlob(path.Dir(srcSpec.FilePath)) {
+		return &errors.CLIError{
+			What:       "Error while copying",
+			Why:        "Glob patterns are only supported in the last element of the remote source path",
+			Additional: []string{"For example, koyeb instance cp <instance_id>:/var/log/*.log . is supported, but not <instance_id>:/var/*/app.log"},
+			Orig:       nil,
+			Solution:   "Fix the paths and try again",
+		}
+	}
+
 	if srcSpec.InstanceID == "" && dstSpec.InstanceID == "" {
 		return &errors.CLIError{
 			What:       "Error while copying",
@@ -28,10 +28,11 @@ func NewDatabaseCmd() *cobra.Command {
 	databaseCmd.AddCommand(listDbCmd)
 
 	getDbCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get database",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Get),
+		Use:               "get NAME",
+		Short:             "Get database",
+		ValidArgsFunction: completeArgs(completeDatabases),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Get),
 	}
 	getDbCmd.Flags().String("app", "", "Database application. If the application does not exist, it will be created. Can also be provided in the database name with the format `app-name/database-name`")
 	databaseCmd.AddCommand(getDbCmd)
@@ -58,12 +59,14 @@ func NewDatabaseCmd() *cobra.Command {
 		}),
 	}
 	addCreateDbServiceDefinitionFlags(createDbCmd.Flags())
+	createDbCmd.RegisterFlagCompletionFunc("instance-type", completeDatabaseInstanceTypes) //nolint:errcheck
 	databaseCmd.AddCommand(createDbCmd)
 
 	updateDbCmd := &cobra.Command{
-		Use:   "update NAME",
-		Short: "Update database",
-		Args:  cobra.ExactArgs(1),
+		Use:               "update NAME",
+		Short:             "Update database",
+		ValidArgsFunction: completeArgs(completeDatabases),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			serviceName, err := serviceHandler.parseServiceName(cmd, args[0])
 			if err != nil {
@@ -113,13 +116,15 @@ func NewDatabaseCmd() *cobra.Command {
 		}),
 	}
 	addUpdateDbServiceDefinitionFlags(updateDbCmd.Flags())
+	updateDbCmd.RegisterFlagCompletionFunc("instance-type", completeDatabaseInstanceTypes) //nolint:errcheck
 	databaseCmd.AddCommand(updateDbCmd)
 
 	deleteDbCmd := &cobra.Command{
-		Use:   "delete NAME",
-		Short: "Delete database",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Delete),
+		Use:               "delete NAME",
+		Short:             "Delete database",
+		ValidArgsFunction: completeArgs(completeDatabases),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Delete),
 	}
 	databaseCmd.AddCommand(deleteDbCmd)
 
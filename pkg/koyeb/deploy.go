@@ -13,184 +13,249 @@ import (
 
 func NewDeployCmd() *cobra.Command {
 	h := NewDeployHandler()
-	appHandler := NewAppHandler()
-	archiveHandler := NewArchiveHandler()
-	serviceHandler := NewServiceHandler()
 
 	deployCmd := &cobra.Command{
-		Use:   "deploy <path> <app>/<service>",
-		Short: "Deploy a directory to Koyeb",
-		Args:  cobra.ExactArgs(2),
-		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
-			appName, err := serviceHandler.parseAppName(cmd, args[1])
-			if err != nil {
-				return err
-			}
+		Use:               "deploy <path> <app>/<service>",
+		Short:             "Deploy a directory to Koyeb",
+		ValidArgsFunction: completeArgs(nil, completeServices),
+		Example: `
+# Deploy the current directory, and redeploy it each time a file changes
+$> koyeb deploy . myapp/myservice --watch
+
+# Deploy two services of a monorepo from a single archive. Each service is built
+# with the Dockerfile of its directory, and the build context is the root of the archive.
+$> koyeb deploy . myapp --service api:services/api --service worker:services/worker
+`,
+		Args: cobra.ExactArgs(2),
+		RunE: WithCLIContext(h.Deploy),
+	}
+	deployCmd.Flags().String("app", "", "Service application. Can also be provided in the service name with the format <app>/<service>")
+	deployCmd.Flags().Bool("wait", false, "Waits until the deployment is done")
+	deployCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
+	deployCmd.Flags().Bool("dry-run", false, "Show the files which would be included in the archive, the largest files and directories and the compressed size, without uploading the archive nor deploying")
+	deployCmd.Flags().Bool("logs", false, "Tail the build logs, then the runtime logs of the deployment while waiting. Implies --wait")
+	deployCmd.Flags().Bool("watch", false, "Watch the directory, and redeploy it each time a file included in the archive changes. The deployment in progress is canceled when a newer change is deployed, and the logs of the current deployment are displayed")
+	deployCmd.Flags().StringSlice("service", nil, "Deploy a service from a sub-directory, with the format NAME:DIRECTORY. The service is built with the Dockerfile of DIRECTORY.\n"+
+		"When set, the second argument is the application, and the services are deployed concurrently from a single archive.\n"+
+		"Archive sources have no working directory: the services are always built with the docker builder, and the build context is the root of the archive. --archive-builder, --archive-buildpack-* and --archive-docker-dockerfile can not be used, the other --archive-docker-* flags apply to all the services.\n"+
+		"To deploy multiple services, use the flag multiple times.")
+	deployCmd.MarkFlagsMutuallyExclusive("service", "watch")
+	deployCmd.MarkFlagsMutuallyExclusive("service", "logs")
+	deployCmd.MarkFlagsMutuallyExclusive("watch", "wait")
+	deployCmd.MarkFlagsMutuallyExclusive("watch", "logs")
+	deployCmd.MarkFlagsMutuallyExclusive("watch", "dry-run")
+
+	h.serviceHandler.addServiceDefinitionFlagsForAllSources(deployCmd.Flags())
+	h.serviceHandler.addServiceDefinitionFlagsForArchiveSource(deployCmd.Flags())
+	deployCmd.RegisterFlagCompletionFunc("type", completeServiceTypes) //nolint:errcheck
+	return deployCmd
+}
 
-			appId, err := h.GetAppId(ctx, appName)
-			if err != nil {
-				return err
-			}
+func NewDeployHandler() *DeployHandler {
+	return &DeployHandler{
+		appHandler:     NewAppHandler(),
+		archiveHandler: NewArchiveHandler(),
+		serviceHandler: NewServiceHandler(),
+	}
+}
 
-			if appId != "" {
-				log.Infof("Application `%s` already exists, using it", appName)
-			} else {
-				log.Infof("Application `%s` does not exist. Creating it", appName)
-				createApp := koyeb.NewCreateAppWithDefaults()
-				createApp.SetName(appName)
-				createAppReply, err := appHandler.CreateApp(ctx, createApp)
-				if err != nil {
-					return err
-				}
-				appId = *createAppReply.GetApp().Id
-			}
+type DeployHandler struct {
+	appHandler     *AppHandler
+	archiveHandler *ArchiveHandler
+	serviceHandler *ServiceHandler
+}
 
-			serviceName, err := serviceHandler.parseServiceNameWithoutApp(cmd, args[1])
-			if err != nil {
-				return err
-			}
+func (h *DeployHandler) Deploy(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	if err := h.serviceHandler.ParseArchiveFlags(cmd.Flags(), h.archiveHandler); err != nil {
+		return err
+	}
 
-			serviceId, err := h.GetServiceId(ctx, appId, serviceName)
-			if err != nil {
-				return err
-			}
+	if GetBoolFlags(cmd, "dry-run") {
+		return h.archiveHandler.ListArchive(ctx, args[0])
+	}
 
-			err = serviceHandler.ParseArchiveIgnoreDirectories(cmd.Flags(), archiveHandler)
-			if err != nil {
-				return err
-			}
+	if services, _ := cmd.Flags().GetStringSlice("service"); len(services) > 0 {
+		return h.DeployServices(ctx, cmd, args[0], args[1], services)
+	}
 
-			if serviceId == "" {
-				createService := koyeb.NewCreateServiceWithDefaults()
-				createDefinition := koyeb.NewDeploymentDefinitionWithDefaults()
-
-				log.Infof("Creating and uploading an archive from `%s`", args[0])
-				archiveReply, err := archiveHandler.CreateArchive(ctx, args[0])
-				if err != nil {
-					return err
-				}
-
-				createDefinition.Name = koyeb.PtrString(serviceName)
-
-				archive := createDefinition.GetArchive()
-				archive.Id = archiveReply.GetArchive().Id
-				createDefinition.SetArchive(archive)
-				createDefinition.Git = nil
-				createDefinition.Docker = nil
-				createService.SetDefinition(*createDefinition)
-
-				// Update definition with the flags provided by the user.
-				if err := serviceHandler.parseServiceDefinitionFlags(ctx, cmd.Flags(), createDefinition); err != nil {
-					return err
-				}
-				createService.SetDefinition(*createDefinition)
-
-				// Parse and set lifecycle
-				lifecycle := serviceHandler.parseLifeCycle(cmd.Flags(), nil)
-				if lifecycle != nil {
-					createService.SetLifeCycle(*lifecycle)
-				}
-
-				log.Infof("Creating the new service `%s`", serviceName)
-				if err := serviceHandler.Create(ctx, cmd, []string{args[1]}, createService); err != nil {
-					return err
-				}
-			} else {
-				updateService := koyeb.NewUpdateServiceWithDefaults()
-				latestDeploy, resp, err := ctx.Client.DeploymentsApi.
-					ListDeployments(ctx.Context).
-					Limit("1").
-					ServiceId(serviceId).
-					Execute()
-				if err != nil {
-					return errors.NewCLIErrorFromAPIError(
-						fmt.Sprintf("Error while updating the service `%s`", args[0]),
-						err,
-						resp,
-					)
-				}
-
-				if len(latestDeploy.GetDeployments()) == 0 {
-					return &errors.CLIError{
-						What: "Error while updating the service",
-						Why:  "we couldn't find the latest deployment of your service",
-						Additional: []string{
-							"When you create a service for the first time, it can take a few seconds for the first deployment to be created.",
-							"We need to fetch the configuration of this latest deployment to update your service.",
-						},
-						Orig:     nil,
-						Solution: "Try again in a few seconds. If the problem persists, delete the service and create it again.",
-					}
-				}
-
-				updateDefinition := latestDeploy.GetDeployments()[0].Definition
-
-				log.Infof("Creating and uploading an archive from `%s`", args[0])
-				archiveReply, err := archiveHandler.CreateArchive(ctx, args[0])
-				if err != nil {
-					return err
-				}
-
-				archive := updateDefinition.GetArchive()
-				archive.Id = archiveReply.GetArchive().Id
-				updateDefinition.SetArchive(archive)
-				updateDefinition.Git = nil
-				updateDefinition.Docker = nil
-				updateService.SetDefinition(*updateDefinition)
-
-				// Update definition with the flags provided by the user.
-				// parseServiceDefinitionFlags expects to have an archive
-				// source, otherwise it would try to get the --git or --docker
-				// flags which are not present.
-				err = serviceHandler.parseServiceDefinitionFlags(ctx, cmd.Flags(), updateDefinition)
-				if err != nil {
-					return err
-				}
-				updateService.SetDefinition(*updateDefinition)
-
-				// Get current service to access lifecycle
-				currentService, resp, err := ctx.Client.ServicesApi.GetService(ctx.Context, serviceId).Execute()
-				if err != nil {
-					return errors.NewCLIErrorFromAPIError(
-						fmt.Sprintf("Error while fetching service `%s`", serviceName),
-						err,
-						resp,
-					)
-				}
-
-				// Parse and set lifecycle
-				var currentLifeCycle *koyeb.ServiceLifeCycle
-				if currentService.Service.HasLifeCycle() {
-					lc := currentService.Service.GetLifeCycle()
-					currentLifeCycle = &lc
-				}
-				lifecycle := serviceHandler.parseLifeCycle(cmd.Flags(), currentLifeCycle)
-				if lifecycle != nil {
-					updateService.SetLifeCycle(*lifecycle)
-				}
-
-				log.Infof("Updating the existing service `%s`", serviceName)
-				if err := serviceHandler.Update(ctx, cmd, []string{args[1]}, updateService); err != nil {
-					return err
-				}
-			}
-			return nil
-		}),
+	if GetBoolFlags(cmd, "watch") {
+		return h.Watch(ctx, cmd, args)
 	}
-	deployCmd.Flags().String("app", "", "Service application. Can also be provided in the service name with the format <app>/<service>")
-	deployCmd.Flags().Bool("wait", false, "Waits until the deployment is done")
-	deployCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
 
-	serviceHandler.addServiceDefinitionFlagsForAllSources(deployCmd.Flags())
-	serviceHandler.addServiceDefinitionFlagsForArchiveSource(deployCmd.Flags())
-	return deployCmd
+	_, err := h.deploy(ctx, cmd, args[0], args[1])
+	return err
 }
 
-func NewDeployHandler() *DeployHandler {
-	return &DeployHandler{}
+// deploy uploads an archive of path, and creates or updates the service. It
+// returns the ID of the service.
+func (h *DeployHandler) deploy(ctx *CLIContext, cmd *cobra.Command, path string, serviceArg string) (string, error) {
+	appName, err := h.serviceHandler.parseAppName(cmd, serviceArg)
+	if err != nil {
+		return "", err
+	}
+
+	appId, err := h.getOrCreateApp(ctx, appName)
+	if err != nil {
+		return "", err
+	}
+
+	log.Infof("Creating and uploading an archive from `%s`", path)
+	archiveReply, err := h.archiveHandler.CreateArchive(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	uploaded := archiveReply.GetArchive()
+	return h.deployArchive(ctx, cmd, appId, serviceArg, uploaded.GetId())
 }
 
-type DeployHandler struct {
+// getOrCreateApp returns the ID of the application, and creates it if it does
+// not exist.
+func (h *DeployHandler) getOrCreateApp(ctx *CLIContext, appName string) (string, error) {
+	appId, err := h.GetAppId(ctx, appName)
+	if err != nil {
+		return "", err
+	}
+
+	if appId != "" {
+		log.Infof("Application `%s` already exists, using it", appName)
+		return appId, nil
+	}
+
+	log.Infof("Application `%s` does not exist. Creating it", appName)
+	createApp := koyeb.NewCreateAppWithDefaults()
+	createApp.SetName(appName)
+	createAppReply, err := h.appHandler.CreateApp(ctx, createApp)
+	if err != nil {
+		return "", err
+	}
+	return *createAppReply.GetApp().Id, nil
+}
+
+// deployArchive creates or updates the service serviceArg of the application
+// appId to deploy the archive archiveID. The customize functions are applied
+// to the definition after the flags. It returns the ID of the service.
+func (h *DeployHandler) deployArchive(ctx *CLIContext, cmd *cobra.Command, appId string, serviceArg string, archiveID string, customize ...func(*koyeb.DeploymentDefinition)) (string, error) {
+	serviceName, err := h.serviceHandler.parseServiceNameWithoutApp(cmd, serviceArg)
+	if err != nil {
+		return "", err
+	}
+
+	serviceId, err := h.GetServiceId(ctx, appId, serviceName)
+	if err != nil {
+		return "", err
+	}
+
+	if serviceId == "" {
+		createService := koyeb.NewCreateServiceWithDefaults()
+		createDefinition := koyeb.NewDeploymentDefinitionWithDefaults()
+
+		createDefinition.Name = koyeb.PtrString(serviceName)
+
+		archive := createDefinition.GetArchive()
+		archive.Id = koyeb.PtrString(archiveID)
+		createDefinition.SetArchive(archive)
+		createDefinition.Git = nil
+		createDefinition.Docker = nil
+		createService.SetDefinition(*createDefinition)
+
+		// Update definition with the flags provided by the user.
+		if err := h.serviceHandler.parseServiceDefinitionFlags(ctx, cmd.Flags(), createDefinition); err != nil {
+			return "", err
+		}
+		for _, fn := range customize {
+			fn(createDefinition)
+		}
+		createService.SetDefinition(*createDefinition)
+
+		// Parse and set lifecycle
+		lifecycle := h.serviceHandler.parseLifeCycle(cmd.Flags(), nil)
+		if lifecycle != nil {
+			createService.SetLifeCycle(*lifecycle)
+		}
+
+		log.Infof("Creating the new service `%s`", serviceName)
+		if err := h.serviceHandler.Create(ctx, cmd, []string{serviceArg}, createService); err != nil {
+			return "", err
+		}
+		return h.GetServiceId(ctx, appId, serviceName)
+	}
+
+	updateService := koyeb.NewUpdateServiceWithDefaults()
+	latestDeploy, resp, err := ctx.Client.DeploymentsApi.
+		ListDeployments(ctx.Context).
+		Limit("1").
+		ServiceId(serviceId).
+		Execute()
+	if err != nil {
+		return "", errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while updating the service `%s`", serviceName),
+			err,
+			resp,
+		)
+	}
+
+	if len(latestDeploy.GetDeployments()) == 0 {
+		return "", &errors.CLIError{
+			What: "Error while updating the service",
+			Why:  "we couldn't find the latest deployment of your service",
+			Additional: []string{
+				"When you create a service for the first time, it can take a few seconds for the first deployment to be created.",
+				"We need to fetch the configuration of this latest deployment to update your service.",
+			},
+			Orig:     nil,
+			Solution: "Try again in a few seconds. If the problem persists, delete the service and create it again.",
+		}
+	}
+
+	updateDefinition := latestDeploy.GetDeployments()[0].Definition
+
+	archive := updateDefinition.GetArchive()
+	archive.Id = koyeb.PtrString(archiveID)
+	updateDefinition.SetArchive(archive)
+	updateDefinition.Git = nil
+	updateDefinition.Docker = nil
+	updateService.SetDefinition(*updateDefinition)
+
+	// Update definition with the flags provided by the user.
+	// parseServiceDefinitionFlags expects to have an archive
+	// source, otherwise it would try to get the --git or --docker
+	// flags which are not present.
+	err = h.serviceHandler.parseServiceDefinitionFlags(ctx, cmd.Flags(), updateDefinition)
+	if err != nil {
+		return "", err
+	}
+	for _, fn := range customize {
+		fn(updateDefinition)
+	}
+	updateService.SetDefinition(*updateDefinition)
+
+	// Get current service to access lifecycle
+	currentService, resp, err := ctx.Client.ServicesApi.GetService(ctx.Context, serviceId).Execute()
+	if err != nil {
+		return "", errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while fetching service `%s`", serviceName),
+			err,
+			resp,
+		)
+	}
+
+	// Parse and set lifecycle
+	var currentLifeCycle *koyeb.ServiceLifeCycle
+	if currentService.Service.HasLifeCycle() {
+		lc := currentService.Service.GetLifeCycle()
+		currentLifeCycle = &lc
+	}
+	lifecycle := h.serviceHandler.parseLifeCycle(cmd.Flags(), currentLifeCycle)
+	if lifecycle != nil {
+		updateService.SetLifeCycle(*lifecycle)
+	}
+
+	log.Infof("Updating the existing service `%s`", serviceName)
+	if err := h.serviceHandler.Update(ctx, cmd, []string{serviceArg}, updateService); err != nil {
+		return "", err
+	}
+	return serviceId, nil
 }
 
 // Return the app id if it exists, otherwise return an empty string.
@@ -0,0 +1,306 @@
+package koyeb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/idmapper"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/renderer"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// deployServiceSpec is a service deployed from a sub-directory of the archive,
+// set with --service NAME:DIRECTORY.
+type deployServiceSpec struct {
+	name string
+	// dockerfile is the path of the Dockerfile of the service, relative to the root of the archive
+	dockerfile string
+}
+
+// parseDeployServices parses the values of --service. DIRECTORY is relative to
+// path, and must contain a Dockerfile. It can also be the path of the
+// Dockerfile itself, for example services/worker/Dockerfile.worker.
+func parseDeployServices(path string, values []string) ([]deployServiceSpec, error) {
+	ret := []deployServiceSpec{}
+	seen := map[string]bool{}
+
+	for _, value := range values {
+		name, dir, found := strings.Cut(value, ":")
+		if !found || name == "" || dir == "" {
+			return nil, &errors.CLIError{
+				What:       "Error while parsing --service",
+				Why:        fmt.Sprintf("unable to parse the value `%s`", value),
+				Additional: []string{"The expected format is NAME:DIRECTORY, for example --service api:services/api"},
+				Orig:       nil,
+				Solution:   "Fix the value of --service and try again",
+			}
+		}
+		if seen[name] {
+			return nil, &errors.CLIError{
+				What:       "Error while parsing --service",
+				Why:        fmt.Sprintf("the service `%s` is set multiple times", name),
+				Additional: nil,
+				Orig:       nil,
+				Solution:   "Fix the value of --service and try again",
+			}
+		}
+		seen[name] = true
+
+		dockerfile := filepath.Join(path, dir)
+		if info, err := os.Stat(dockerfile); err == nil && info.IsDir() {
+			dockerfile = filepath.Join(dockerfile, "Dockerfile")
+		}
+		if _, err := os.Stat(dockerfile); err != nil {
+			return nil, &errors.CLIError{
+				What: "Error while parsing --service",
+				Why:  fmt.Sprintf("unable to find the Dockerfile of the service `%s`", name),
+				Additional: []string{
+					"The services deployed with --service are built with the docker builder, using the Dockerfile of their directory. The build context is the root of the archive.",
+					"Archive sources have no working directory: to build a service from a sub-directory with buildpacks, deploy it from git with --git-workdir.",
+				},
+				Orig:     err,
+				Solution: errors.CLIErrorSolution(fmt.Sprintf("Add a Dockerfile to %s, or set the path of the Dockerfile with --service %s:<path>/Dockerfile", dir, name)),
+			}
+		}
+		relative, err := filepath.Rel(path, dockerfile)
+		if err != nil || strings.HasPrefix(relative, "..") {
+			return nil, &errors.CLIError{
+				What:       "Error while parsing --service",
+				Why:        fmt.Sprintf("the directory of the service `%s` is not in %s", name, path),
+				Additional: nil,
+				Orig:       err,
+				Solution:   "Set a directory relative to the directory to deploy",
+			}
+		}
+		ret = append(ret, deployServiceSpec{name: name, dockerfile: filepath.ToSlash(relative)})
+	}
+	return ret, nil
+}
+
+// deployServicesUnsupportedFlags are the builder settings which can not be used
+// with --service. Archive sources have no working directory, so the services
+// are built with the docker builder and the Dockerfile of their directory.
+var deployServicesUnsupportedFlags = []string{
+	"archive-builder",
+	"archive-buildpack-build-command",
+	"archive-buildpack-run-command",
+	"archive-docker-dockerfile",
+}
+
+// checkDeployServicesFlags returns an error if a builder setting which can
+// not be used with --service is set.
+func checkDeployServicesFlags(flags *pflag.FlagSet) error {
+	for _, name := range deployServicesUnsupportedFlags {
+		if flag := flags.Lookup(name); flag != nil && flag.Changed {
+			return &errors.CLIError{
+				What: "Error while deploying the services",
+				Why:  fmt.Sprintf("--%s can not be used with --service", name),
+				Additional: []string{
+					"Archive sources have no working directory, so the services deployed with --service are built with the docker builder and the Dockerfile of their directory.",
+					"The other --archive-docker-* settings apply to all the services.",
+				},
+				Orig:     nil,
+				Solution: "Remove the flag, or deploy the services from git with --git-workdir to build them with buildpacks",
+			}
+		}
+	}
+	return nil
+}
+
+// DeployServices uploads a single archive of path, and creates or updates a
+// service for each --service, built with its own Dockerfile.
+func (h *DeployHandler) DeployServices(ctx *CLIContext, cmd *cobra.Command, path string, appName string, values []string) error {
+	if err := checkDeployServicesFlags(cmd.Flags()); err != nil {
+		return err
+	}
+
+	specs, err := parseDeployServices(path, values)
+	if err != nil {
+		return err
+	}
+
+	appId, err := h.getOrCreateApp(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Creating and uploading an archive from `%s`", path)
+	archiveReply, err := h.archiveHandler.CreateArchive(ctx, path)
+	if err != nil {
+		return err
+	}
+	uploaded := archiveReply.GetArchive()
+
+	reply := h.deployServices(ctx, cmd, appId, appName, uploaded.GetId(), specs)
+	ctx.Renderer.Render(reply)
+
+	failed := []string{}
+	for _, item := range reply.items {
+		if item.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s: %s", item.Service, item.Error))
+		}
+	}
+	if len(failed) > 0 {
+		return &errors.CLIError{
+			What:       "Error while deploying the services",
+			Why:        fmt.Sprintf("%d of %d services could not be deployed", len(failed), len(specs)),
+			Additional: failed,
+			Orig:       nil,
+			Solution:   "Fix the errors and run the command again",
+		}
+	}
+	return nil
+}
+
+// deployServices concurrently creates or updates the services of specs to
+// deploy the archive, and waits for their deployments if --wait is set.
+//
+// The mapper resolving the names is not safe for concurrent use, so each
+// service is deployed with its own copy of the context and its own mapper.
+// Create and Update do not wait nor render the services: the summary table is
+// the only output.
+func (h *DeployHandler) deployServices(ctx *CLIContext, cmd *cobra.Command, appId string, appName string, archiveID string, specs []deployServiceSpec) *DeployServicesReply {
+	quiet := &DeployHandler{
+		appHandler:     h.appHandler,
+		archiveHandler: h.archiveHandler,
+		serviceHandler: &ServiceHandler{quiet: true},
+	}
+	wait := GetBoolFlags(cmd, "wait")
+	waitTimeout, _ := cmd.Flags().GetDuration("wait-timeout")
+
+	reply := &DeployServicesReply{items: make([]deployServiceResult, len(specs))}
+	var wg sync.WaitGroup
+	for idx, spec := range specs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			serviceCtx := *ctx
+			serviceCtx.Mapper = idmapper.NewMapper(ctx.Context, ctx.Client)
+			reply.items[idx] = quiet.deployService(&serviceCtx, cmd, appId, appName, archiveID, spec, wait, waitTimeout)
+		}()
+	}
+	wg.Wait()
+	return reply
+}
+
+// deployService creates or updates the service of spec, and waits for its
+// deployment if wait is set.
+func (h *DeployHandler) deployService(ctx *CLIContext, cmd *cobra.Command, appId string, appName string, archiveID string, spec deployServiceSpec, wait bool, waitTimeout time.Duration) deployServiceResult {
+	result := deployServiceResult{Service: spec.name, Dockerfile: spec.dockerfile}
+	serviceArg := fmt.Sprintf("%s/%s", appName, spec.name)
+
+	serviceID, err := h.deployArchive(ctx, cmd, appId, serviceArg, archiveID, func(definition *koyeb.DeploymentDefinition) {
+		setArchiveDockerfile(definition, spec.dockerfile)
+	})
+	if err != nil {
+		result.Status = "FAILED"
+		result.Error = err.Error()
+		return result
+	}
+	deployment, err := h.serviceHandler.getLatestDeployment(ctx, serviceID, serviceArg)
+	if err != nil {
+		result.Status = "FAILED"
+		result.Error = err.Error()
+		return result
+	}
+	result.Deployment = deployment.GetId()
+	result.Status = string(deployment.GetStatus())
+
+	if wait {
+		status, err := waitDeployment(ctx, result.Deployment, waitTimeout)
+		if status != "" {
+			result.Status = string(status)
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}
+	return result
+}
+
+// waitDeployment polls the deployment until its build and its start are over,
+// and returns its last status.
+func waitDeployment(ctx *CLIContext, deploymentID string, timeout time.Duration) (koyeb.DeploymentStatus, error) {
+	ctxd, cancel := context.WithTimeout(ctx.Context, timeout)
+	defer cancel()
+
+	status := koyeb.DeploymentStatus("")
+	for range ticker(ctxd, 2*time.Second) {
+		res, resp, err := ctx.Client.DeploymentsApi.GetDeployment(ctxd, deploymentID).Execute()
+		if err != nil {
+			if ctxd.Err() != nil {
+				break
+			}
+			return status, errors.NewCLIErrorFromAPIError("Error while fetching deployment", err, resp)
+		}
+		status = res.Deployment.GetStatus()
+		switch status {
+		case koyeb.DEPLOYMENTSTATUS_ERROR, koyeb.DEPLOYMENTSTATUS_DEGRADED, koyeb.DEPLOYMENTSTATUS_UNHEALTHY, koyeb.DEPLOYMENTSTATUS_CANCELED, koyeb.DEPLOYMENTSTATUS_STOPPED, koyeb.DEPLOYMENTSTATUS_ERRORING:
+			return status, fmt.Errorf("deployment %s ended in status: %s", deploymentID[:8], status)
+		case koyeb.DEPLOYMENTSTATUS_STARTING, koyeb.DEPLOYMENTSTATUS_PENDING, koyeb.DEPLOYMENTSTATUS_PROVISIONING, koyeb.DEPLOYMENTSTATUS_ALLOCATING:
+		default:
+			return status, nil
+		}
+	}
+	return status, fmt.Errorf("deployment %s still in progress, --wait timed out", deploymentID[:8])
+}
+
+// setArchiveDockerfile configures the archive source of the definition to
+// build with the docker builder and the given Dockerfile. The other docker
+// builder settings set with --archive-docker-* are kept.
+func setArchiveDockerfile(definition *koyeb.DeploymentDefinition, dockerfile string) {
+	archive := definition.GetArchive()
+	docker := archive.GetDocker()
+	docker.SetDockerfile(dockerfile)
+	archive.SetDocker(docker)
+	archive.Buildpack = nil
+	definition.SetArchive(archive)
+}
+
+type deployServiceResult struct {
+	Service    string `json:"service"`
+	Dockerfile string `json:"dockerfile"`
+	Deployment string `json:"deployment_id"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+type DeployServicesReply struct {
+	items []deployServiceResult
+}
+
+func (DeployServicesReply) Title() string {
+	return "Services"
+}
+
+func (r *DeployServicesReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.items)
+}
+
+func (r *DeployServicesReply) Headers() []string {
+	return []string{"service", "dockerfile", "deployment", "status"}
+}
+
+func (r *DeployServicesReply) Fields() []map[string]string {
+	resp := []map[string]string{}
+	for _, item := range r.items {
+		resp = append(resp, map[string]string{
+			"service":    item.Service,
+			"dockerfile": item.Dockerfile,
+			"deployment": renderer.FormatID(item.Deployment, false),
+			"status":     item.Status,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,220 @@
+package koyeb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/idmapper"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/renderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDeployServices(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"api/Dockerfile", "worker/Dockerfile.worker", "web/index.html"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(file)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, file), nil, 0o644))
+	}
+	// A directory with a Dockerfile next to root
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "Dockerfile"), nil, 0o644))
+
+	tests := map[string]struct {
+		values   []string
+		expected []deployServiceSpec
+		err      string
+	}{
+		"directories": {
+			values: []string{"api:api", "worker:worker/Dockerfile.worker"},
+			expected: []deployServiceSpec{
+				{name: "api", dockerfile: "api/Dockerfile"},
+				{name: "worker", dockerfile: "worker/Dockerfile.worker"},
+			},
+		},
+		"invalid_format": {
+			values: []string{"api"},
+			err:    "unable to parse the value `api`",
+		},
+		"empty_name": {
+			values: []string{":api"},
+			err:    "unable to parse the value `:api`",
+		},
+		"duplicate": {
+			values: []string{"api:api", "api:worker/Dockerfile.worker"},
+			err:    "the service `api` is set multiple times",
+		},
+		"no_dockerfile": {
+			values: []string{"web:web"},
+			err:    "unable to find the Dockerfile of the service `web`",
+		},
+		"relative_path_inside_root": {
+			values: []string{"api:../" + filepath.Base(root) + "/api"},
+			expected: []deployServiceSpec{
+				{name: "api", dockerfile: "api/Dockerfile"},
+			},
+		},
+		"outside_root": {
+			values: []string{"other:../" + filepath.Base(outside)},
+			err:    "the directory of the service `other` is not in",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			specs, err := parseDeployServices(root, tc.values)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, specs)
+		})
+	}
+}
+
+func TestCheckDeployServicesFlags(t *testing.T) {
+	tests := map[string]struct {
+		args []string
+		err  string
+	}{
+		"docker_settings":   {args: []string{"--archive-docker-target", "prod"}},
+		"builder":           {args: []string{"--archive-builder", "docker"}, err: "--archive-builder can not be used with --service"},
+		"buildpack_command": {args: []string{"--archive-buildpack-run-command", "npm start"}, err: "--archive-buildpack-run-command can not be used with --service"},
+		"dockerfile":        {args: []string{"--archive-docker-dockerfile", "Dockerfile"}, err: "--archive-docker-dockerfile can not be used with --service"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewDeployCmd()
+			require.NoError(t, cmd.ParseFlags(tc.args))
+			err := checkDeployServicesFlags(cmd.Flags())
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// countRenderer counts the resources rendered.
+type countRenderer struct {
+	mu     sync.Mutex
+	titles []string
+}
+
+func (r *countRenderer) Render(item renderer.ApiResources) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.titles = append(r.titles, item.Title())
+}
+
+func (r *countRenderer) RenderSeparator() {}
+
+// fakeDeployAPI implements the endpoints used to create services and wait for
+// their deployments.
+type fakeDeployAPI struct {
+	mu       sync.Mutex
+	services map[string]string
+}
+
+func (f *fakeDeployAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reply := map[string]interface{}{}
+	switch {
+	case r.URL.Path == "/v1/apps":
+		reply = map[string]interface{}{
+			"apps":  []map[string]string{{"id": "11111111-1111-4111-8111-111111111111", "name": "myapp"}},
+			"count": 1,
+		}
+	case strings.HasPrefix(r.URL.Path, "/v1/apps/"):
+		reply = map[string]interface{}{"app": map[string]string{"id": "11111111-1111-4111-8111-111111111111", "name": "myapp"}}
+	case r.URL.Path == "/v1/services" && r.Method == http.MethodPost:
+		body := koyeb.CreateService{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		name := body.Definition.GetName()
+		f.services[name] = fmt.Sprintf("%08d-2222-4222-8222-222222222222", len(f.services))
+		reply = map[string]interface{}{"service": map[string]string{"id": f.services[name], "name": name}}
+	case r.URL.Path == "/v1/services":
+		services := []map[string]string{}
+		if id, ok := f.services[r.URL.Query().Get("name")]; ok {
+			services = append(services, map[string]string{"id": id, "name": r.URL.Query().Get("name")})
+		}
+		reply = map[string]interface{}{"services": services, "count": len(services)}
+	case r.URL.Path == "/v1/deployments":
+		reply = map[string]interface{}{
+			"deployments": []map[string]string{{"id": "d-" + r.URL.Query().Get("service_id"), "status": "PENDING"}},
+			"count":       1,
+		}
+	case strings.HasPrefix(r.URL.Path, "/v1/deployments/"):
+		reply = map[string]interface{}{"deployment": map[string]string{"id": strings.TrimPrefix(r.URL.Path, "/v1/deployments/"), "status": "HEALTHY"}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply) //nolint:errcheck
+}
+
+// isolatedTransport sends each request with a new transport. The locks of a
+// shared transport would synchronize the goroutines, and hide the races from
+// the race detector.
+type isolatedTransport struct{}
+
+func (isolatedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	transport := &http.Transport{DisableKeepAlives: true}
+	return transport.RoundTrip(r)
+}
+
+// TestDeployServices deploys several services at once. Run it with -race to
+// check the services deployed concurrently do not share the mapper.
+func TestDeployServices(t *testing.T) {
+	server := httptest.NewServer(&fakeDeployAPI{services: map[string]string{}})
+	defer server.Close()
+
+	config := koyeb.NewConfiguration()
+	config.Servers[0].URL = server.URL
+	config.HTTPClient = &http.Client{Transport: isolatedTransport{}}
+	client := koyeb.NewAPIClient(config)
+	render := &countRenderer{}
+	ctx := &CLIContext{
+		Context:  context.Background(),
+		Client:   client,
+		Mapper:   idmapper.NewMapper(context.Background(), client),
+		Renderer: render,
+	}
+
+	h := NewDeployHandler()
+	cmd := NewDeployCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--wait"}))
+
+	specs := []deployServiceSpec{
+		{name: "api", dockerfile: "api/Dockerfile"},
+		{name: "worker", dockerfile: "worker/Dockerfile"},
+		{name: "cron", dockerfile: "cron/Dockerfile"},
+	}
+	reply := h.deployServices(ctx, cmd, "11111111-1111-4111-8111-111111111111", "myapp", "archive", specs)
+
+	require.Len(t, reply.items, len(specs))
+	for idx, item := range reply.items {
+		assert.Equal(t, specs[idx].name, item.Service)
+		assert.Empty(t, item.Error)
+		assert.Equal(t, "HEALTHY", item.Status)
+	}
+	// Create does not render the services, the caller renders the summary
+	assert.Empty(t, render.titles)
+}
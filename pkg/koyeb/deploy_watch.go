@@ -0,0 +1,177 @@
+package koyeb
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/archive"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// deployWatchDebounce is the delay without changes after which the directory
+// is redeployed, so saving several files at once triggers a single deployment.
+const deployWatchDebounce = time.Second
+
+// Watch deploys the directory, then redeploys it each time a file included in
+// the archive changes, until the user presses Ctrl+C. The logs of the current
+// deployment are displayed between the deployments.
+func (h *DeployHandler) Watch(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	path, serviceArg := args[0], args[1]
+
+	opts, err := h.archiveHandler.archiveOptions(path)
+	if err != nil {
+		return err
+	}
+	basePath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return &errors.CLIError{
+			What:       "Error while watching the directory",
+			Why:        fmt.Sprintf("unable to watch the directory `%s`", path),
+			Additional: nil,
+			Orig:       err,
+			Solution:   errors.SolutionUpdateOrIssue,
+		}
+	}
+	defer watcher.Close()
+
+	if err := watchDirectories(watcher, basePath, basePath, opts); err != nil {
+		return &errors.CLIError{
+			What:       "Error while watching the directory",
+			Why:        fmt.Sprintf("unable to watch the directory `%s`", path),
+			Additional: []string{"On Linux, the number of directories which can be watched is limited by fs.inotify.max_user_watches."},
+			Orig:       err,
+			Solution:   "Exclude the directories which are not needed with .koyebignore or --archive-ignore-dir",
+		}
+	}
+
+	serviceID, err := h.deploy(ctx, cmd, path, serviceArg)
+	if err != nil {
+		return err
+	}
+	deploymentID, follower := h.followLatestDeployment(ctx, cmd, serviceID, serviceArg)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	log.Infof("Watching `%s` for changes. Press Ctrl+C to stop.", path)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-interrupt:
+			if follower != nil {
+				follower.Cancel()
+			}
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warnf("Error while watching `%s`: %s", path, err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			relativePath, err := filepath.Rel(basePath, event.Name)
+			if err != nil {
+				continue
+			}
+			info, statErr := os.Stat(event.Name)
+			isDir := statErr == nil && info.IsDir()
+			if opts.Excludes(filepath.ToSlash(relativePath), isDir) {
+				continue
+			}
+			// New directories need to be watched too
+			if isDir && event.Op&fsnotify.Create != 0 {
+				if err := watchDirectories(watcher, basePath, event.Name, opts); err != nil {
+					log.Warnf("Unable to watch the directory `%s`: %s", event.Name, err)
+				}
+			}
+			log.Debugf("Change detected: %s", event)
+			debounce = time.After(deployWatchDebounce)
+		case <-debounce:
+			debounce = nil
+			log.Infof("Changes detected in `%s`, redeploying", path)
+
+			if follower != nil {
+				follower.Cancel()
+				follower = nil
+			}
+			h.cancelInProgressDeployment(ctx, deploymentID)
+
+			serviceID, err = h.deploy(ctx, cmd, path, serviceArg)
+			if err != nil {
+				// Keep watching: the next change might fix the error
+				log.Errorf("Error while redeploying: %s", err)
+				continue
+			}
+			deploymentID, follower = h.followLatestDeployment(ctx, cmd, serviceID, serviceArg)
+			log.Infof("Watching `%s` for changes. Press Ctrl+C to stop.", path)
+		}
+	}
+}
+
+// watchDirectories adds dir and its sub-directories which are included in the
+// archive of basePath to the watcher.
+func watchDirectories(watcher *fsnotify.Watcher, basePath string, dir string, opts archive.Options) error {
+	return filepath.Walk(dir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(basePath, file)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		if relativePath != "." && opts.SkipsDir(relativePath) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(file)
+	})
+}
+
+// followLatestDeployment starts printing the logs of the latest deployment of
+// the service, and returns its ID. If the deployment cannot be found, a warning
+// is displayed and the follower is nil.
+func (h *DeployHandler) followLatestDeployment(ctx *CLIContext, cmd *cobra.Command, serviceID string, serviceArg string) (string, *DeploymentLogsFollower) {
+	deployment, err := h.serviceHandler.getLatestDeployment(ctx, serviceID, serviceArg)
+	if err != nil {
+		log.Warnf("Unable to display the logs of the deployment: %s", err)
+		return "", nil
+	}
+	return deployment.GetId(), FollowDeploymentLogs(ctx, deployment.GetId(), GetBoolFlags(cmd, "full"))
+}
+
+// cancelInProgressDeployment cancels the deployment if it is still building or
+// starting. Errors are not fatal: the new deployment is created anyway.
+func (h *DeployHandler) cancelInProgressDeployment(ctx *CLIContext, deploymentID string) {
+	if deploymentID == "" {
+		return
+	}
+
+	res, _, err := ctx.Client.DeploymentsApi.GetDeployment(ctx.Context, deploymentID).Execute()
+	if err != nil || !slices.Contains(deploymentInProgressStatuses, res.Deployment.GetStatus()) {
+		return
+	}
+
+	log.Infof("Canceling the deployment %s which is still in progress", deploymentID)
+	if _, resp, err := ctx.Client.DeploymentsApi.CancelDeployment(ctx.Context, deploymentID).Execute(); err != nil {
+		log.Warnf("%s", errors.NewCLIErrorFromAPIError(fmt.Sprintf("Unable to cancel the deployment %s", deploymentID), err, resp))
+	}
+}
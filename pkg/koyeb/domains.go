@@ -14,10 +14,11 @@ func NewDomainCmd() *cobra.Command {
 	}
 
 	getDomainCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get domain",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Get),
+		Use:               "get NAME",
+		Short:             "Get domain",
+		ValidArgsFunction: completeArgs(completeDomains),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Get),
 	}
 	domainCmd.AddCommand(getDomainCmd)
 
@@ -53,26 +54,29 @@ func NewDomainCmd() *cobra.Command {
 	domainCmd.AddCommand(deleteDomainCmd)
 
 	refreshDomainCmd := &cobra.Command{
-		Use:   "refresh NAME",
-		Short: "Refresh a custom domain verification status",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Refresh),
+		Use:               "refresh NAME",
+		Short:             "Refresh a custom domain verification status",
+		ValidArgsFunction: completeArgs(completeDomains),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Refresh),
 	}
 	domainCmd.AddCommand(refreshDomainCmd)
 
 	attachDomainCmd := &cobra.Command{
-		Use:   "attach NAME APP",
-		Short: "Attach a custom domain to an existing app",
-		Args:  cobra.ExactArgs(2),
-		RunE:  WithCLIContext(h.Attach),
+		Use:               "attach NAME APP",
+		Short:             "Attach a custom domain to an existing app",
+		ValidArgsFunction: completeArgs(completeDomains, completeApps),
+		Args:              cobra.ExactArgs(2),
+		RunE:              WithCLIContext(h.Attach),
 	}
 	domainCmd.AddCommand(attachDomainCmd)
 
 	detachDomainCmd := &cobra.Command{
-		Use:   "detach NAME",
-		Short: "Detach a custom domain from the app it is currently attached to",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Detach),
+		Use:               "detach NAME",
+		Short:             "Detach a custom domain from the app it is currently attached to",
+		ValidArgsFunction: completeArgs(completeDomains),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Detach),
 	}
 	domainCmd.AddCommand(detachDomainCmd)
 
@@ -0,0 +1,369 @@
+// This package parses and writes environment files using the dotenv syntax. It
+// is used by the CLI to import environment variables with --env-file, and to
+// export them with `koyeb service env export`.
+//
+// The supported syntax is:
+//
+//	# Comments start with a #
+//	KEY=value                  # Unquoted values are trimmed and inline comments are removed
+//	export KEY=value           # The "export" prefix is ignored
+//	KEY='literal ${NOT_EXPANDED}'
+//	KEY="line1\nline2 ${OTHER}" # Escape sequences and variable expansion
+//	KEY="multiline
+//	value"
+//	KEY=${OTHER:-default}      # Expansion with a default value
+//	KEY=@my-secret             # Reference to the secret "my-secret"
+//
+// Variables are expanded from the variables previously defined in the file,
+// then from the lookup function given to Parse. Only unquoted values starting
+// with @ are secret references: use quotes to set a value starting with @.
+package dotenv
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Variable is an environment variable read from or written to a dotenv file.
+type Variable struct {
+	Key string
+	// Value is the value of the variable, or the name of the secret if Secret is true.
+	Value  string
+	Secret bool
+}
+
+// ParseError is returned by Parse when the content is not a valid dotenv file.
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+var keyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+type parser struct {
+	data   string
+	pos    int
+	line   int
+	lookup func(string) (string, bool)
+	vars   map[string]string
+}
+
+// Parse reads the dotenv content from r. lookup is used to expand the
+// variables which are not defined in the file, and can be nil.
+func Parse(r io.Reader, lookup func(string) (string, bool)) ([]Variable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if lookup == nil {
+		lookup = func(string) (string, bool) { return "", false }
+	}
+	p := &parser{
+		data:   strings.ReplaceAll(string(data), "\r\n", "\n"),
+		line:   1,
+		lookup: lookup,
+		vars:   map[string]string{},
+	}
+	return p.parse()
+}
+
+func (p *parser) parse() ([]Variable, error) {
+	ret := []Variable{}
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return ret, nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		variable, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		variable.Key = key
+		if !variable.Secret {
+			p.vars[key] = variable.Value
+		}
+		if err := p.expectEndOfLine(); err != nil {
+			return nil, err
+		}
+		ret = append(ret, variable)
+	}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *parser) peek() byte {
+	return p.data[p.pos]
+}
+
+func (p *parser) advance() byte {
+	c := p.data[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &ParseError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// skipBlank skips spaces, tabs and newlines.
+func (p *parser) skipBlank() {
+	for !p.eof() && strings.IndexByte(" \t\n", p.peek()) >= 0 {
+		p.advance()
+	}
+}
+
+// skipSpaces skips spaces and tabs, but not newlines.
+func (p *parser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.advance()
+	}
+}
+
+func (p *parser) skipLine() {
+	for !p.eof() && p.advance() != '\n' {
+	}
+}
+
+func (p *parser) parseKey() (string, error) {
+	start := p.pos
+	for !p.eof() && strings.IndexByte("=\n", p.peek()) < 0 {
+		p.advance()
+	}
+	key := strings.TrimSpace(p.data[start:p.pos])
+	if strings.HasPrefix(key, "export ") || strings.HasPrefix(key, "export\t") {
+		key = strings.TrimSpace(key[len("export"):])
+	}
+	if p.eof() || p.peek() != '=' {
+		return "", p.errorf("expected KEY=VALUE, got %q", key)
+	}
+	if !keyRegexp.MatchString(key) {
+		return "", p.errorf("invalid variable name %q", key)
+	}
+	p.advance() // Skip =
+	return key, nil
+}
+
+func (p *parser) parseValue() (Variable, error) {
+	p.skipSpaces()
+	if p.eof() {
+		return Variable{}, nil
+	}
+
+	switch p.peek() {
+	case '\'':
+		value, err := p.parseSingleQuoted()
+		return Variable{Value: value}, err
+	case '"':
+		value, err := p.parseDoubleQuoted()
+		return Variable{Value: value}, err
+	}
+
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		// An inline comment starts with a # preceded by a space
+		if p.peek() == '#' && p.pos > start && (p.data[p.pos-1] == ' ' || p.data[p.pos-1] == '\t') {
+			break
+		}
+		p.advance()
+	}
+	raw := strings.TrimSpace(p.data[start:p.pos])
+	if strings.HasPrefix(raw, "@") {
+		if len(raw) == 1 {
+			return Variable{}, p.errorf("missing secret name after @")
+		}
+		return Variable{Value: raw[1:], Secret: true}, nil
+	}
+	return Variable{Value: p.expand(raw)}, nil
+}
+
+func (p *parser) parseSingleQuoted() (string, error) {
+	line := p.line
+	p.advance() // Skip the opening quote
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.advance()
+	}
+	if p.eof() {
+		return "", &ParseError{Line: line, Msg: "unterminated single-quoted value"}
+	}
+	value := p.data[start:p.pos]
+	p.advance() // Skip the closing quote
+	return value, nil
+}
+
+func (p *parser) parseDoubleQuoted() (string, error) {
+	line := p.line
+	p.advance() // Skip the opening quote
+
+	var buf strings.Builder
+	for {
+		if p.eof() {
+			return "", &ParseError{Line: line, Msg: "unterminated double-quoted value"}
+		}
+		c := p.advance()
+		switch c {
+		case '"':
+			return buf.String(), nil
+		case '\\':
+			if p.eof() {
+				continue
+			}
+			escaped := p.advance()
+			switch escaped {
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case '"', '\\', '$':
+				buf.WriteByte(escaped)
+			default:
+				buf.WriteByte('\\')
+				buf.WriteByte(escaped)
+			}
+		case '$':
+			buf.WriteString(p.expandReference())
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// expand replaces the $VAR and ${VAR} references of an unquoted value.
+func (p *parser) expand(value string) string {
+	sub := &parser{data: value, lookup: p.lookup, vars: p.vars}
+	var buf strings.Builder
+	for !sub.eof() {
+		c := sub.advance()
+		if c == '$' {
+			buf.WriteString(sub.expandReference())
+		} else {
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+// expandReference is called after a $ has been consumed, and returns the value
+// of the referenced variable. ${VAR:-default} returns default if VAR is unset
+// or empty. A $ which is not followed by a variable name is kept as is.
+func (p *parser) expandReference() string {
+	if p.eof() {
+		return "$"
+	}
+	if p.peek() == '{' {
+		end := strings.IndexByte(p.data[p.pos:], '}')
+		if end < 0 || strings.ContainsAny(p.data[p.pos:p.pos+end], "\"\n") {
+			return "$"
+		}
+		expr := p.data[p.pos+1 : p.pos+end]
+		for i := 0; i <= end; i++ {
+			p.advance()
+		}
+		name, def, hasDefault := strings.Cut(expr, ":-")
+		value := p.resolve(name)
+		if hasDefault && value == "" {
+			return def
+		}
+		return value
+	}
+
+	start := p.pos
+	for !p.eof() && isNameChar(p.peek(), p.pos == start) {
+		p.advance()
+	}
+	if p.pos == start {
+		return "$"
+	}
+	return p.resolve(p.data[start:p.pos])
+}
+
+func (p *parser) resolve(name string) string {
+	if value, ok := p.vars[name]; ok {
+		return value
+	}
+	value, _ := p.lookup(name)
+	return value
+}
+
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
+
+// expectEndOfLine makes sure that only spaces and an optional comment follow a value.
+func (p *parser) expectEndOfLine() error {
+	p.skipSpaces()
+	if p.eof() {
+		return nil
+	}
+	switch p.peek() {
+	case '\n':
+		p.advance()
+		return nil
+	case '#':
+		p.skipLine()
+		return nil
+	}
+	return p.errorf("unexpected character %q after the value", p.peek())
+}
+
+var unquotedValueRegexp = regexp.MustCompile(`^[A-Za-z0-9_./:,+=-]*$`)
+
+// Write writes the variables to w using the dotenv syntax. The output can be read back with Parse.
+func Write(w io.Writer, vars []Variable) error {
+	for _, v := range vars {
+		var line string
+		switch {
+		case v.Secret:
+			line = fmt.Sprintf("%s=@%s\n", v.Key, v.Value)
+		case unquotedValueRegexp.MatchString(v.Value):
+			line = fmt.Sprintf("%s=%s\n", v.Key, v.Value)
+		default:
+			line = fmt.Sprintf("%s=%s\n", v.Key, quote(v.Value))
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func quote(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		`$`, `\$`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+	)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,122 @@
+package dotenv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/koyeb", true
+		}
+		return "", false
+	}
+
+	tests := map[string]struct {
+		content     string
+		expected    []Variable
+		expectedErr bool
+	}{
+		"empty": {
+			content:  "\n# only a comment\n\n",
+			expected: []Variable{},
+		},
+		"unquoted": {
+			content: "FOO=bar\nexport BAZ = qux  # comment\nEMPTY=\nHASH=a#b\n",
+			expected: []Variable{
+				{Key: "FOO", Value: "bar"},
+				{Key: "BAZ", Value: "qux"},
+				{Key: "EMPTY", Value: ""},
+				{Key: "HASH", Value: "a#b"},
+			},
+		},
+		"quotes": {
+			content: "A='lit ${HOME} \\n'\nB=\"tab\\there \\\"q\\\" \\$HOME\"\nC=\"multi\nline\" # comment\n",
+			expected: []Variable{
+				{Key: "A", Value: "lit ${HOME} \\n"},
+				{Key: "B", Value: "tab\there \"q\" $HOME"},
+				{Key: "C", Value: "multi\nline"},
+			},
+		},
+		"expansion": {
+			content: "BASE=/srv\nDIR=${BASE}/app\nH=\"$HOME/x\"\nDEF=${MISSING:-fallback}\nUNSET=$MISSING\nPRICE=5$\n",
+			expected: []Variable{
+				{Key: "BASE", Value: "/srv"},
+				{Key: "DIR", Value: "/srv/app"},
+				{Key: "H", Value: "/home/koyeb/x"},
+				{Key: "DEF", Value: "fallback"},
+				{Key: "UNSET", Value: ""},
+				{Key: "PRICE", Value: "5$"},
+			},
+		},
+		"secrets": {
+			content: "DB=@database-url\nLITERAL=\"@not-a-secret\"\n",
+			expected: []Variable{
+				{Key: "DB", Value: "database-url", Secret: true},
+				{Key: "LITERAL", Value: "@not-a-secret"},
+			},
+		},
+		"crlf": {
+			content: "A=1\r\nB=2\r\n",
+			expected: []Variable{
+				{Key: "A", Value: "1"},
+				{Key: "B", Value: "2"},
+			},
+		},
+		"missing_equal": {
+			content:     "FOO\n",
+			expectedErr: true,
+		},
+		"invalid_key": {
+			content:     "1FOO=bar\n",
+			expectedErr: true,
+		},
+		"unterminated_quote": {
+			content:     "FOO=\"bar\n",
+			expectedErr: true,
+		},
+		"garbage_after_quote": {
+			content:     "FOO='bar' baz\n",
+			expectedErr: true,
+		},
+		"empty_secret": {
+			content:     "FOO=@\n",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			vars, err := Parse(strings.NewReader(tc.content), lookup)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, vars)
+			}
+		})
+	}
+}
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	vars := []Variable{
+		{Key: "PLAIN", Value: "value"},
+		{Key: "URL", Value: "postgres://user@host:5432/db?sslmode=require"},
+		{Key: "SPACES", Value: "hello world"},
+		{Key: "SPECIAL", Value: "a\"b\\c$d\ne\tf 'g' #h"},
+		{Key: "AT", Value: "@literal"},
+		{Key: "EMPTY", Value: ""},
+		{Key: "SECRET", Value: "my-secret", Secret: true},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, vars))
+
+	parsed, err := Parse(&buf, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, vars, parsed)
+}
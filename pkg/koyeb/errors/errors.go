@@ -21,6 +21,15 @@ type CLIError struct {
 	Solution   CLIErrorSolution // How to solve the error. For example: "update the CLI"
 	ASCII      bool             // Whether to use only ASCII characters in the error message
 	Icon       string           // Icon to display in the error message for non-ASCII output
+	ExitCode   int              // Exit code of the CLI. Defaults to 1 when zero.
+}
+
+// GetExitCode returns the exit code of the CLI for this error.
+func (e *CLIError) GetExitCode() int {
+	if e.ExitCode == 0 {
+		return 1
+	}
+	return e.ExitCode
 }
 
 func (e *CLIError) Error() string {
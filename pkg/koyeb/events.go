@@ -0,0 +1,210 @@
+package koyeb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/renderer"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// eventsWatchInterval is the delay between two polls of --watch.
+	eventsWatchInterval = 5 * time.Second
+	// eventsRecentDeployments is the number of deployments of a service whose
+	// events are displayed by koyeb service events.
+	eventsRecentDeployments = 5
+)
+
+// resourceEvent is an event of an instance, a deployment or a service.
+type resourceEvent struct {
+	ID           string                 `json:"id"`
+	When         time.Time              `json:"when"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   string                 `json:"resource_id"`
+	Type         string                 `json:"type"`
+	Message      string                 `json:"message"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// addEventsFlags adds the flags shared by instance events and service events.
+func addEventsFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("watch", false, "Keep running and display the new events")
+	cmd.Flags().Int("limit", 50, "Maximum number of events to retrieve for each resource")
+}
+
+// renderEvents displays the events returned by fetch in chronological order.
+// With --watch, fetch is called periodically and the new events are displayed
+// until the user presses Ctrl+C.
+func renderEvents(ctx *CLIContext, cmd *cobra.Command, fetch func(limit int) ([]resourceEvent, error)) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit <= 0 {
+		return &errors.CLIError{
+			What:       "Error while listing the events",
+			Why:        "the limit must be greater than 0",
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Fix the value of --limit and try again",
+		}
+	}
+	full := GetBoolFlags(cmd, "full")
+
+	events, err := fetch(limit)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, event := range events {
+		seen[event.ID] = true
+	}
+	sortEvents(events)
+	ctx.Renderer.Render(&EventsReply{events: events, full: full})
+
+	if !GetBoolFlags(cmd, "watch") {
+		return nil
+	}
+	for range ticker(ctx.Context, eventsWatchInterval) {
+		events, err := fetch(limit)
+		if err != nil {
+			log.Warnf("Unable to retrieve the new events: %s", err)
+			continue
+		}
+		newEvents := []resourceEvent{}
+		for _, event := range events {
+			if !seen[event.ID] {
+				seen[event.ID] = true
+				newEvents = append(newEvents, event)
+			}
+		}
+		if len(newEvents) > 0 {
+			sortEvents(newEvents)
+			ctx.Renderer.Render(&EventsReply{events: newEvents, full: full})
+		}
+	}
+	return nil
+}
+
+func sortEvents(events []resourceEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].When.Before(events[j].When)
+	})
+}
+
+// listInstanceEvents returns the most recent events of the instances.
+func listInstanceEvents(ctx *CLIContext, instanceIDs []string, limit int) ([]resourceEvent, error) {
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+	res, resp, err := ctx.Client.InstancesApi.ListInstanceEvents(ctx.Context).
+		InstanceIds(instanceIDs).
+		Order("desc").
+		Limit(strconv.Itoa(limit)).
+		Execute()
+	if err != nil {
+		return nil, errors.NewCLIErrorFromAPIError("Error while listing the instance events", err, resp)
+	}
+
+	ret := []resourceEvent{}
+	for _, event := range res.GetEvents() {
+		ret = append(ret, resourceEvent{
+			ID:           event.GetId(),
+			When:         event.GetWhen(),
+			ResourceType: "instance",
+			ResourceID:   event.GetInstanceId(),
+			Type:         event.GetType(),
+			Message:      event.GetMessage(),
+			Metadata:     event.GetMetadata(),
+		})
+	}
+	return ret, nil
+}
+
+// listDeploymentEvents returns the most recent events of the deployment.
+func listDeploymentEvents(ctx *CLIContext, deploymentID string, limit int) ([]resourceEvent, error) {
+	if deploymentID == "" {
+		return nil, nil
+	}
+	res, resp, err := ctx.Client.DeploymentsApi.ListDeploymentEvents(ctx.Context).
+		DeploymentId(deploymentID).
+		Order("desc").
+		Limit(strconv.Itoa(limit)).
+		Execute()
+	if err != nil {
+		return nil, errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while listing the events of the deployment `%s`", deploymentID), err, resp)
+	}
+
+	ret := []resourceEvent{}
+	for _, event := range res.GetEvents() {
+		ret = append(ret, resourceEvent{
+			ID:           event.GetId(),
+			When:         event.GetWhen(),
+			ResourceType: "deployment",
+			ResourceID:   event.GetDeploymentId(),
+			Type:         event.GetType(),
+			Message:      event.GetMessage(),
+			Metadata:     event.GetMetadata(),
+		})
+	}
+	return ret, nil
+}
+
+// listServiceEvents returns the most recent events of the service.
+func listServiceEvents(ctx *CLIContext, serviceID string, limit int) ([]resourceEvent, error) {
+	res, resp, err := ctx.Client.ServicesApi.ListServiceEvents(ctx.Context).
+		ServiceId(serviceID).
+		Order("desc").
+		Limit(strconv.Itoa(limit)).
+		Execute()
+	if err != nil {
+		return nil, errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while listing the events of the service `%s`", serviceID), err, resp)
+	}
+
+	ret := []resourceEvent{}
+	for _, event := range res.GetEvents() {
+		ret = append(ret, resourceEvent{
+			ID:           event.GetId(),
+			When:         event.GetWhen(),
+			ResourceType: "service",
+			ResourceID:   event.GetServiceId(),
+			Type:         event.GetType(),
+			Message:      event.GetMessage(),
+			Metadata:     event.GetMetadata(),
+		})
+	}
+	return ret, nil
+}
+
+type EventsReply struct {
+	events []resourceEvent
+	full   bool
+}
+
+func (EventsReply) Title() string {
+	return "Events"
+}
+
+func (r *EventsReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.events)
+}
+
+func (r *EventsReply) Headers() []string {
+	return []string{"when", "resource", "type", "message"}
+}
+
+func (r *EventsReply) Fields() []map[string]string {
+	resp := []map[string]string{}
+	for _, event := range r.events {
+		resp = append(resp, map[string]string{
+			"when":     renderer.FormatTime(event.When),
+			"resource": fmt.Sprintf("%s %s", event.ResourceType, renderer.FormatID(event.ResourceID, r.full)),
+			"type":     event.Type,
+			"message":  event.Message,
+		})
+	}
+	return resp
+}
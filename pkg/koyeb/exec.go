@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -49,8 +50,48 @@ func NewExecAPIClient(apiUrl string, token string) (*ExecAPIClient, error) {
 	}, nil
 }
 
-func (client *ExecAPIClient) Exec(ctx context.Context, id ExecId, cmd []string) (int, error) {
-	stdStreams, cleanup, err := GetStdStreams()
+// execStdinChunkSize is the maximum size of the standard input sent in a
+// single message. Large chunks reduce the overhead of the messages when data
+// is piped to the command.
+const execStdinChunkSize = 32 * 1024
+
+// ExecExitCodeConnectionClosed is the exit code of koyeb instance exec and
+// koyeb service exec when the connection is closed before the command exits.
+const ExecExitCodeConnectionClosed = 255
+
+// execModesHelp documents the interactive and non-interactive modes of exec.
+const execModesHelp = `
+
+If the standard input and output are terminals, the command runs interactively in a TTY.
+
+Otherwise, or with --no-tty, the command runs without a TTY, which is suited to scripts and data pipelines:
+  - the standard input is streamed as is, and closed on the command side when it reaches EOF
+  - the standard output and error of the command are written separately and unmodified, so binary data can be piped
+
+The command exits with the exit code of the remote command, or 255 if the connection is closed before the command exits.`
+
+// ExecOptions configures ExecAPIClient.Exec.
+type ExecOptions struct {
+	// Recorder, if set, records the session
+	Recorder *SessionRecorder
+	// NoTTY disables the allocation of a TTY, even if the standard input and
+	// output are terminals
+	NoTTY bool
+}
+
+// Exec runs the command with the standard streams of the process.
+//
+// If the standard input and output are terminals, and unless opts.NoTTY is
+// set, the command runs in a TTY and the terminal is set in raw mode.
+// Otherwise, the command runs without a TTY: the standard input is streamed as
+// is and closed when it reaches EOF, and the standard output and error of the
+// command are written unmodified to the standard output and error, so binary
+// data can be piped through the command.
+//
+// The returned code is the exit code of the command, or -1 if the connection
+// was closed before the command exited.
+func (client *ExecAPIClient) Exec(ctx context.Context, id ExecId, cmd []string, opts ExecOptions) (int, error) {
+	stdStreams, cleanup, err := GetStdStreams(!opts.NoTTY)
 	if err != nil {
 		return 0, errors.Wrap(err, "could not get standard streams")
 	}
@@ -60,13 +101,21 @@ func (client *ExecAPIClient) Exec(ctx context.Context, id ExecId, cmd []string)
 		}
 	}()
 
+	stdStreams.Recorder = opts.Recorder
+	stdStreams.DisableTty = opts.NoTTY
 	return client.ExecWithStreams(ctx, stdStreams, id, cmd)
 }
 
 func isTty(stdin io.Reader, stdout io.Writer) bool {
-	_, isStdinTerminal := term.GetFdInfo(stdin)
-	_, isStdoutTerminal := term.GetFdInfo(stdout)
-	return isStdinTerminal && isStdoutTerminal
+	return isTerminal(stdin) && isTerminal(stdout)
+}
+
+// isTerminal returns whether v is a terminal. Besides *os.File, it accepts the
+// readers and writers wrapping a terminal which implement Fd, like the input
+// of koyeb ui.
+func isTerminal(v interface{}) bool {
+	f, ok := v.(interface{ Fd() uintptr })
+	return ok && term.IsTerminal(f.Fd())
 }
 
 func (client *ExecAPIClient) ExecWithStreams(
@@ -78,9 +127,16 @@ func (client *ExecAPIClient) ExecWithStreams(
 	defer cancel()
 
 	termResizeCh := watchTermSize(ctx, stdStreams.Stdout)
-	tty := isTty(stdStreams.Stdin, stdStreams.Stdout)
+	tty := !stdStreams.DisableTty && isTty(stdStreams.Stdin, stdStreams.Stdout)
+
+	stdout, stderr := stdStreams.Stdout, stdStreams.Stderr
+	if stdStreams.Recorder != nil {
+		stdout = stdStreams.Recorder.Writer(stdout)
+		stderr = stdStreams.Recorder.Writer(stderr)
+		termResizeCh = stdStreams.Recorder.Resizes(ctx, termResizeCh)
+	}
 
-	e := NewExecutor(stdStreams.Stdin, stdStreams.Stdout, stdStreams.Stderr, cmd, tty, id, termResizeCh)
+	e := NewExecutor(stdStreams.Stdin, stdout, stderr, cmd, tty, id, termResizeCh)
 	return e.Run(ctx, client.url, client.header)
 }
 
@@ -101,10 +157,16 @@ type ApiExecCommandRequest struct {
 	IdType *koyeb.ExecCommandRequestIdType `json:"idType,omitempty"`
 }
 
-func closeOn(c *websocket.Conn, s ...os.Signal) {
+func closeOn(ctx context.Context, c *websocket.Conn, s ...os.Signal) {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, s...)
-	<-sigs
+	defer signal.Stop(sigs)
+	select {
+	case <-sigs:
+	case <-ctx.Done():
+		// The command is over, stop listening for signals
+		return
+	}
 	// gorilla.websocket does not support implicit graceful shutdowns.
 	// We need to manually send a control message to the server. Once
 	// done, the client websocket (our) will go to closing mode. When
@@ -149,6 +211,16 @@ type Executor struct {
 
 	cmd []string
 	id  ExecId
+
+	// writeMu serializes the writes to the websocket, which does not support
+	// concurrent writers
+	writeMu sync.Mutex
+}
+
+func (e *Executor) writeJSON(c *websocket.Conn, v interface{}) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return c.WriteJSON(v)
 }
 
 func NewExecutor(stdin io.Reader, stdout, stderr io.Writer, cmd []string, tty bool, id ExecId, termResizeCh <-chan *TerminalSize) *Executor {
@@ -168,8 +240,9 @@ func (e *Executor) Run(ctx context.Context, url *url.URL, header http.Header) (i
 	if err != nil {
 		return -1, errors.Wrapf(err, "could not dial %s", url)
 	}
+	defer c.Close()
 
-	go closeOn(c, syscall.SIGINT, syscall.SIGTERM)
+	go closeOn(ctx, c, syscall.SIGINT, syscall.SIGTERM)
 
 	r := &ApiExecCommandRequest{
 		Id:     &e.id.Id,
@@ -217,7 +290,7 @@ func (e *Executor) pushOne(ctx context.Context, c *websocket.Conn, r *ApiExecCom
 	if c == nil {
 		return errors.New("fatal: need an open connection")
 	}
-	err := c.WriteJSON(r)
+	err := e.writeJSON(c, r)
 	if err != nil {
 		return errors.Wrap(err, "failed sending data to remote server")
 	}
@@ -225,7 +298,8 @@ func (e *Executor) pushOne(ctx context.Context, c *websocket.Conn, r *ApiExecCom
 }
 
 func (e *Executor) pushTermResizes(ctx context.Context, c *websocket.Conn, from <-chan *TerminalSize) <-chan error {
-	errChan := make(chan error)
+	// Buffered, so the goroutine can exit even if Run has already returned
+	errChan := make(chan error, 1)
 	go func() {
 		if c == nil {
 			errChan <- errors.New("fatal: need an open connection")
@@ -235,7 +309,11 @@ func (e *Executor) pushTermResizes(ctx context.Context, c *websocket.Conn, from
 			select {
 			case <-ctx.Done():
 				return
-			case r := <-from:
+			case r, ok := <-from:
+				if !ok {
+					// The terminal size is no longer watched
+					return
+				}
 				if r == nil {
 					errChan <- errors.New("cannot resize term to nil size")
 					return
@@ -247,7 +325,7 @@ func (e *Executor) pushTermResizes(ctx context.Context, c *websocket.Conn, from
 				body := koyeb.NewExecCommandRequestBody()
 				body.SetTtySize(*resize)
 
-				err := c.WriteJSON(&ApiExecCommandRequest{
+				err := e.writeJSON(c, &ApiExecCommandRequest{
 					Body: body,
 				})
 				if err != nil {
@@ -261,14 +339,15 @@ func (e *Executor) pushTermResizes(ctx context.Context, c *websocket.Conn, from
 }
 
 func (e *Executor) pushMany(ctx context.Context, c *websocket.Conn, from io.Reader) <-chan error {
-	errChan := make(chan error)
+	// Buffered, so the goroutine can exit even if Run has already returned
+	errChan := make(chan error, 1)
 	if c == nil {
 		errChan <- errors.New("fatal: need an open connection")
 		return errChan
 	}
 
 	go func() {
-		data := make([]byte, 4096)
+		data := make([]byte, execStdinChunkSize)
 		for {
 			if ctx.Err() != nil {
 				return
@@ -289,7 +368,7 @@ func (e *Executor) pushMany(ctx context.Context, c *websocket.Conn, from io.Read
 				body.SetCommand(e.cmd)
 				body.SetStdin(*io)
 
-				writeErr := c.WriteJSON(&ApiExecCommandRequest{
+				writeErr := e.writeJSON(c, &ApiExecCommandRequest{
 					Id:     &e.id.Id,
 					IdType: &e.id.Type,
 					Body:   body,
@@ -308,7 +387,7 @@ func (e *Executor) pushMany(ctx context.Context, c *websocket.Conn, from io.Read
 				body.SetCommand(e.cmd)
 				body.SetStdin(*io)
 
-				writeErr := c.WriteJSON(&ApiExecCommandRequest{
+				writeErr := e.writeJSON(c, &ApiExecCommandRequest{
 					Id:     &e.id.Id,
 					IdType: &e.id.Type,
 					Body:   body,
@@ -328,8 +407,9 @@ func (e *Executor) pushMany(ctx context.Context, c *websocket.Conn, from io.Read
 }
 
 func (e *Executor) report(ctx context.Context, c *websocket.Conn, stdout, stderr io.Writer) (<-chan error, <-chan int) {
-	errCh := make(chan error)
-	exitCodeCh := make(chan int)
+	// Buffered, so the goroutine can exit even if Run has already returned
+	errCh := make(chan error, 1)
+	exitCodeCh := make(chan int, 1)
 	if c == nil {
 		errCh <- errors.New("fatal: need an open connection")
 		return errCh, exitCodeCh
@@ -379,8 +459,8 @@ func (e *Executor) report(ctx context.Context, c *websocket.Conn, stdout, stderr
 				errCh <- errors.Wrap(err, "reporting to stdio failed")
 				return
 			}
-			if frame.Result != nil && *frame.Result.Exited {
-				exitCodeCh <- int(*frame.Result.ExitCode)
+			if frame.Result != nil && frame.Result.GetExited() {
+				exitCodeCh <- int(frame.Result.GetExitCode())
 				return
 			}
 		}
@@ -433,14 +513,25 @@ type StdStreams struct {
 	Stdin  io.Reader
 	Stdout io.Writer
 	Stderr io.Writer
+	// Recorder, if set, records the output and the terminal resizes
+	Recorder *SessionRecorder
+	// DisableTty runs the command without a TTY, even if Stdin and Stdout are terminals
+	DisableTty bool
 }
 
-func GetStdStreams() (*StdStreams, func() error, error) {
+// GetStdStreams returns the standard streams of the process. If tty is true
+// and the standard input and output are terminals, the terminal is set in raw
+// mode until the returned function is called.
+func GetStdStreams(tty bool) (*StdStreams, func() error, error) {
 	stdin, stdout, stderr := term.StdStreams()
-	fd, isTerm := term.GetFdInfo(stdin)
-	if !isTerm {
-		return nil, nil, errors.New("stdin is not a terminal")
+	if !tty || !isTty(stdin, stdout) {
+		return &StdStreams{
+			Stdin:  stdin,
+			Stdout: stdout,
+			Stderr: stderr,
+		}, func() error { return nil }, nil
 	}
+	fd, _ := term.GetFdInfo(stdin)
 
 	termState, err := term.SetRawTerminal(fd)
 	if err != nil {
@@ -0,0 +1,222 @@
+package koyeb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Default size of the recording when the output is not a terminal.
+const (
+	sessionRecordDefaultWidth  = 80
+	sessionRecordDefaultHeight = 24
+)
+
+// asciicastHeader is the first line of an asciicast v2 file.
+// See https://docs.asciinema.org/manual/asciicast/v2/
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int32             `json:"width"`
+	Height    int32             `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// SessionRecorder records the output and the terminal resizes of an exec
+// session to a file in the asciicast v2 format, which can be played with
+// koyeb exec replay or asciinema.
+type SessionRecorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	w       *bufio.Writer
+	started time.Time
+	// pending holds the end of the output when it stops in the middle of a
+	// UTF-8 character, which is written with the next output
+	pending []byte
+	err     error
+}
+
+// NewSessionRecorder creates the file path and writes the asciicast header.
+// The size of the recording is the size of the terminal of stdout.
+func NewSessionRecorder(path string, title string, command []string) (*SessionRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	header := asciicastHeader{
+		Version:   2,
+		Width:     sessionRecordDefaultWidth,
+		Height:    sessionRecordDefaultHeight,
+		Timestamp: time.Now().Unix(),
+		Command:   strings.Join(command, " "),
+		Title:     title,
+		Env:       map[string]string{"TERM": os.Getenv("TERM")},
+	}
+	if size, err := getTermSize(os.Stdout); err == nil {
+		header.Width, header.Height = size.Width, size.Height
+	}
+
+	r := &SessionRecorder{file: file, w: bufio.NewWriter(file), started: time.Now()}
+	data, err := json.Marshal(header)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := r.w.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// newSessionRecorderFromFlags returns the recorder of the file set with
+// --record, or nil if the flag is not set.
+func newSessionRecorderFromFlags(cmd *cobra.Command, title string, command []string) (*SessionRecorder, error) {
+	path := GetStringFlags(cmd, "record")
+	if path == "" {
+		return nil, nil
+	}
+	recorder, err := NewSessionRecorder(path, title, command)
+	if err != nil {
+		return nil, &errors.CLIError{
+			What:       "Error while recording the session",
+			Why:        fmt.Sprintf("unable to create the file %s", path),
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Make sure the directory exists and is writable",
+		}
+	}
+	log.Infof("Recording the session to %s", path)
+	return recorder, nil
+}
+
+// closeSessionRecorder closes the recorder if it is not nil. Errors are
+// displayed as warnings, as the session itself succeeded.
+func closeSessionRecorder(recorder *SessionRecorder) {
+	if recorder == nil {
+		return
+	}
+	if err := recorder.Close(); err != nil {
+		log.Warnf("Unable to write the recording of the session: %s", err)
+	}
+}
+
+// Writer returns a writer which writes to w and records the data as output.
+func (r *SessionRecorder) Writer(w io.Writer) io.Writer {
+	return &recordWriter{w, r}
+}
+
+// Resizes forwards the terminal sizes of in, and records them.
+func (r *SessionRecorder) Resizes(ctx context.Context, in <-chan *TerminalSize) <-chan *TerminalSize {
+	out := make(chan *TerminalSize)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case size, ok := <-in:
+				if !ok {
+					return
+				}
+				if size != nil {
+					r.event("r", fmt.Sprintf("%dx%d", size.Width, size.Height))
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case out <- size:
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Close flushes the recording and closes the file.
+func (r *SessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.pending) > 0 {
+		r.writeEvent("o", string(r.pending))
+		r.pending = nil
+	}
+	if err := r.w.Flush(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+func (r *SessionRecorder) output(data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data = append(r.pending, data...)
+	// Keep an incomplete UTF-8 character for the next output, as asciicast
+	// events are JSON strings
+	end := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				end = i
+			}
+			break
+		}
+	}
+	r.pending = append([]byte(nil), data[end:]...)
+	if end > 0 {
+		r.writeEvent("o", string(data[:end]))
+	}
+}
+
+func (r *SessionRecorder) event(code string, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeEvent(code, data)
+}
+
+// writeEvent writes an event line: [time, code, data]. Errors are reported by
+// Close, so a failing recording does not interrupt the session.
+func (r *SessionRecorder) writeEvent(code string, data string) {
+	if r.err != nil {
+		return
+	}
+	line, err := json.Marshal([]interface{}{
+		float64(time.Since(r.started).Microseconds()) / 1e6,
+		code,
+		data,
+	})
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	r.err = err
+}
+
+type recordWriter struct {
+	io.Writer
+	recorder *SessionRecorder
+}
+
+func (w *recordWriter) Write(buf []byte) (int, error) {
+	n, err := w.Writer.Write(buf)
+	w.recorder.output(buf[:n])
+	return n, err
+}
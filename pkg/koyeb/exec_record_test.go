@@ -0,0 +1,43 @@
+package koyeb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+
+	recorder, err := NewSessionRecorder(path, "test", []string{"/bin/sh"})
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	w := recorder.Writer(&stdout)
+	// "é" is split between two writes
+	_, err = w.Write([]byte("hello\r\nd\xc3"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("\xa9j\xc3\xa0 vu\r\n"))
+	require.NoError(t, err)
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, "hello\r\ndéjà vu\r\n", stdout.String())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"version":2`)
+	assert.Contains(t, lines[2], `"o","éjà vu\r\n"`)
+
+	var replayed bytes.Buffer
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, ReplaySession(f, &replayed, 100, 0))
+	assert.Equal(t, stdout.String(), replayed.String())
+}
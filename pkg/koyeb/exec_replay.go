@@ -0,0 +1,130 @@
+package koyeb
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// sessionReplayMaxLineSize is the maximum size of an event of a recording.
+const sessionReplayMaxLineSize = 16 * 1024 * 1024
+
+func NewExecCmd() *cobra.Command {
+	execCmd := &cobra.Command{
+		Use:   "exec ACTION",
+		Short: "Exec sessions",
+	}
+
+	replayCmd := &cobra.Command{
+		Use:   "replay FILE",
+		Short: "Play a session recorded with koyeb instance exec --record or koyeb service exec --record",
+		Args:  cobra.ExactArgs(1),
+		Example: `
+# Record an interactive shell, then play it back twice as fast, skipping the pauses longer than 2 seconds
+$> koyeb instance exec <instance_id> --record session.cast -- /bin/sh
+$> koyeb exec replay session.cast --speed 2 --idle-time-limit 2s
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			speed, _ := cmd.Flags().GetFloat64("speed")
+			if speed <= 0 {
+				return &errors.CLIError{
+					What:       "Error while replaying the session",
+					Why:        "the speed must be greater than 0",
+					Additional: nil,
+					Orig:       nil,
+					Solution:   "Fix the value of --speed and try again",
+				}
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return &errors.CLIError{
+					What:       "Error while replaying the session",
+					Why:        fmt.Sprintf("unable to open the file %s", args[0]),
+					Additional: nil,
+					Orig:       err,
+					Solution:   "Make sure the file exists and is readable",
+				}
+			}
+			defer f.Close()
+
+			if err := ReplaySession(f, os.Stdout, speed, GetDurationFlags(cmd, "idle-time-limit")); err != nil {
+				return &errors.CLIError{
+					What:       "Error while replaying the session",
+					Why:        fmt.Sprintf("unable to read the recording %s", args[0]),
+					Additional: []string{"Recordings use the asciicast v2 format"},
+					Orig:       err,
+					Solution:   "Make sure the file has been created with --record",
+				}
+			}
+			return nil
+		},
+	}
+	replayCmd.Flags().Float64("speed", 1, "Playback speed, for example 2 to play the session twice as fast")
+	replayCmd.Flags().Duration("idle-time-limit", 0, "Maximum duration of the pauses between two outputs. By default, pauses are played as recorded")
+	execCmd.AddCommand(replayCmd)
+
+	return execCmd
+}
+
+// ReplaySession writes the output of the asciicast v2 recording r to w,
+// respecting the delays between the events divided by speed. Pauses longer
+// than idleTimeLimit are shortened, unless it is 0.
+func ReplaySession(r io.Reader, w io.Writer, speed float64, idleTimeLimit time.Duration) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), sessionReplayMaxLineSize)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("the recording is empty")
+	}
+	header := asciicastHeader{}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	if header.Version != 2 {
+		return fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+	log.Debugf("Replaying a session recorded on a %dx%d terminal", header.Width, header.Height)
+
+	previous := 0.0
+	for line := 2; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var (
+			timestamp float64
+			code      string
+			data      string
+		)
+		event := []interface{}{&timestamp, &code, &data}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("invalid event on line %d: %w", line, err)
+		}
+
+		delay := time.Duration((timestamp - previous) * float64(time.Second))
+		if idleTimeLimit > 0 && delay > idleTimeLimit {
+			delay = idleTimeLimit
+		}
+		time.Sleep(time.Duration(float64(delay) / speed))
+		previous = timestamp
+
+		// Terminal resizes ("r") and inputs ("i") cannot be played back
+		if code == "o" {
+			if _, err := io.WriteString(w, data); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,73 @@
+package koyeb
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecutorNonInteractive runs an Executor against a server which echoes
+// the standard input, and exits with the code 3 when the standard input is
+// closed.
+func TestExecutorNonInteractive(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		for {
+			req := ApiExecCommandRequest{}
+			if err := c.ReadJSON(&req); err != nil {
+				return
+			}
+			stdin := req.Body.GetStdin()
+			reply := koyeb.NewExecCommandReply()
+			if stdin.GetData() != "" {
+				out := koyeb.NewExecCommandIO()
+				out.SetData(stdin.GetData())
+				reply.SetStdout(*out)
+			}
+			if stdin.GetClose() {
+				reply.SetExited(true)
+				reply.SetExitCode(3)
+			}
+			if err := c.WriteJSON(koyeb.StreamResultOfExecCommandReply{Result: reply}); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	// Binary data, larger than a single message
+	input := make([]byte, 3*execStdinChunkSize+42)
+	_, err := rand.Read(input)
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	e := NewExecutor(bytes.NewReader(input), &stdout, &stderr, []string{"cat"}, false, ExecId{Id: "id", Type: koyeb.EXECCOMMANDREQUESTIDTYPE_INSTANCE_ID}, nil)
+
+	u, err := url.Parse(strings.Replace(server.URL, "http", "ws", 1))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	code, err := e.Run(ctx, u, http.Header{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(input), base64.StdEncoding.EncodeToString(stdout.Bytes()))
+	assert.Empty(t, stderr.Bytes())
+}
@@ -6,8 +6,14 @@ package koyeb
 import (
 	"context"
 	"io"
+
+	"github.com/pkg/errors"
 )
 
 func watchTermSize(ctx context.Context, s io.Writer) <-chan *TerminalSize {
 	return nil
 }
+
+func getTermSize(t io.Writer) (*TerminalSize, error) {
+	return nil, errors.New("not supported")
+}
@@ -3,9 +3,11 @@ package flags_list
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/dotenv"
 	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
 )
 
@@ -70,6 +72,57 @@ func NewEnvListFromFlags(values []string) ([]Flag[koyeb.DeploymentEnv], error) {
 	return ret, nil
 }
 
+// NewEnvListFromFile parses the dotenv file given with --env-file. Variables
+// referenced with ${VAR} and not defined in the file are read from the
+// environment of the CLI.
+func NewEnvListFromFile(path string) ([]Flag[koyeb.DeploymentEnv], error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &errors.CLIError{
+			What:       "Error while configuring the service",
+			Why:        fmt.Sprintf("unable to read the environment file \"%s\"", path),
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Make sure the file exists and is readable, then try again",
+		}
+	}
+	defer file.Close()
+
+	vars, err := dotenv.Parse(file, os.LookupEnv)
+	if err != nil {
+		return nil, &errors.CLIError{
+			What: "Error while configuring the service",
+			Why:  fmt.Sprintf("unable to parse the environment file \"%s\"", path),
+			Additional: []string{
+				"Environment files must contain one KEY=VALUE per line",
+				"To use a secret as a value, specify KEY=@SECRET_NAME",
+				"Values can be quoted with single quotes (literal) or double quotes (with escape sequences and ${VAR} expansion)",
+			},
+			Orig:     err,
+			Solution: "Fix the environment file and try again",
+		}
+	}
+	return NewEnvListFromVariables(vars), nil
+}
+
+// NewEnvListFromVariables converts the variables of a dotenv file to flags.
+func NewEnvListFromVariables(vars []dotenv.Variable) []Flag[koyeb.DeploymentEnv] {
+	ret := make([]Flag[koyeb.DeploymentEnv], 0, len(vars))
+	for _, v := range vars {
+		cliValue := fmt.Sprintf("%s=%s", v.Key, v.Value)
+		if v.Secret {
+			cliValue = fmt.Sprintf("%s=@%s", v.Key, v.Value)
+		}
+		ret = append(ret, &FlagEnv{
+			BaseFlag: BaseFlag{cliValue: cliValue},
+			key:      v.Key,
+			isSecret: v.Secret,
+			value:    v.Value,
+		})
+	}
+	return ret
+}
+
 func (f *FlagEnv) IsEqualTo(env koyeb.DeploymentEnv) bool {
 	return f.key == *env.Key
 }
@@ -54,6 +54,35 @@ func ParseListFlags[ItemType any](
 	return existingItems
 }
 
+// ReplaceListFlags is like ParseListFlags, but removes all the items matching
+// a flag instead of the first one. It is used by `koyeb service env set` and
+// `koyeb service env unset`, where a variable can have one entry per region:
+// setting a variable replaces all its entries with a single new item, and
+// unsetting it removes all its entries.
+func ReplaceListFlags[ItemType any](
+	flags []Flag[ItemType],
+	existingItems []ItemType,
+) []ItemType {
+
+	for _, flag := range flags {
+		newItems := make([]ItemType, 0, len(existingItems))
+		for _, item := range existingItems {
+			if !flag.IsEqualTo(item) {
+				newItems = append(newItems, item)
+			}
+		}
+		if flag.IsDeletionFlag() {
+			if len(newItems) == len(existingItems) {
+				logrus.Warnf("The flag \"%s\" attempts to remove an item, but this item is not configured for the service. This flag will be ignored.", flag)
+			}
+		} else {
+			newItems = append(newItems, *flag.CreateNewItem())
+		}
+		existingItems = newItems
+	}
+	return existingItems
+}
+
 func deleteFromList[ItemType any](flag Flag[ItemType], existingItems []ItemType) ([]ItemType, bool) {
 	for idx, item := range existingItems {
 		if flag.IsEqualTo(item) {
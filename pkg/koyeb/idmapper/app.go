@@ -89,6 +89,17 @@ func (mapper *AppMapper) GetAutoDomain(id string) (string, error) {
 	return name, nil
 }
 
+// Identifiers returns the names of the applications, to suggest them in shell completions.
+func (mapper *AppMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.nameMap.Values(), nil
+}
+
 func (mapper *AppMapper) fetch() error {
 	radix := NewRadixTree()
 
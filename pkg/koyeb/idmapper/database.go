@@ -16,6 +16,9 @@ type DatabaseMapper struct {
 	fetched   bool
 	sidMap    *IDMap
 	nameMap   *IDMap
+	// slugMap only holds <app_name>/<database_name>, nameMap holds all the
+	// forms accepted by ResolveID
+	slugMap *IDMap
 }
 
 func NewDatabaseMapper(ctx context.Context, client *koyeb.APIClient, appMapper *AppMapper) *DatabaseMapper {
@@ -26,6 +29,7 @@ func NewDatabaseMapper(ctx context.Context, client *koyeb.APIClient, appMapper *
 		fetched:   false,
 		sidMap:    NewIDMap(),
 		nameMap:   NewIDMap(),
+		slugMap:   NewIDMap(),
 	}
 }
 
@@ -58,6 +62,17 @@ func (mapper *DatabaseMapper) ResolveID(val string) (string, error) {
 	)
 }
 
+// Identifiers returns the databases as <app_name>/<database_name>, to suggest them in shell completions.
+func (mapper *DatabaseMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.slugMap.Values(), nil
+}
+
 func (mapper *DatabaseMapper) fetch() error {
 	page := int64(0)
 	offset := int64(0)
@@ -83,6 +98,7 @@ func (mapper *DatabaseMapper) fetch() error {
 			if err != nil {
 				return err
 			}
+			mapper.slugMap.Set(service.GetId(), fmt.Sprint(appName, "/", service.GetName()))
 
 			// Possible values:
 			// <app_name>/<service_id>
@@ -47,6 +47,17 @@ func (mapper *DeploymentMapper) ResolveID(val string) (string, error) {
 	)
 }
 
+// Identifiers returns the short IDs of the deployments, to suggest them in shell completions.
+func (mapper *DeploymentMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.sidMap.Values(), nil
+}
+
 func (mapper *DeploymentMapper) fetch() error {
 	radix := NewRadixTree()
 
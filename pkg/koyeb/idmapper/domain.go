@@ -72,6 +72,17 @@ func (mapper *DomainMapper) GetName(id string) (string, error) {
 	return name, nil
 }
 
+// Identifiers returns the names of the domains, to suggest them in shell completions.
+func (mapper *DomainMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.nameMap.Values(), nil
+}
+
 func (mapper *DomainMapper) fetch() error {
 	radix := NewRadixTree()
 
@@ -1,5 +1,7 @@
 package idmapper
 
+import "sort"
+
 // IDMap is a bidirectional map to store value <> id translations.
 // A value could either be a short id, a name and/or a slug.
 type IDMap struct {
@@ -32,3 +34,13 @@ func (idmap *IDMap) Set(id string, val string) {
 	idmap.idCache[id] = val
 	idmap.valCache[val] = id
 }
+
+// Values returns the values of the map, sorted.
+func (idmap *IDMap) Values() []string {
+	values := make([]string, 0, len(idmap.idCache))
+	for _, val := range idmap.idCache {
+		values = append(values, val)
+	}
+	sort.Strings(values)
+	return values
+}
@@ -47,6 +47,17 @@ func (mapper *InstanceMapper) ResolveID(val string) (string, error) {
 	)
 }
 
+// Identifiers returns the short IDs of the instances, to suggest them in shell completions.
+func (mapper *InstanceMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.sidMap.Values(), nil
+}
+
 func (mapper *InstanceMapper) fetch() error {
 	radix := NewRadixTree()
 
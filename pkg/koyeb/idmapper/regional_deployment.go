@@ -47,6 +47,17 @@ func (mapper *RegionalDeploymentMapper) ResolveID(val string) (string, error) {
 	)
 }
 
+// Identifiers returns the short IDs of the regional deployments, to suggest them in shell completions.
+func (mapper *RegionalDeploymentMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.sidMap.Values(), nil
+}
+
 func (mapper *RegionalDeploymentMapper) fetch() error {
 	radix := NewRadixTree()
 
@@ -55,6 +55,17 @@ func (mapper *SecretMapper) ResolveID(val string) (string, error) {
 	)
 }
 
+// Identifiers returns the names of the secrets, to suggest them in shell completions.
+func (mapper *SecretMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.nameMap.Values(), nil
+}
+
 func (mapper *SecretMapper) fetch() error {
 	radix := NewRadixTree()
 
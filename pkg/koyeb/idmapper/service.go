@@ -16,6 +16,7 @@ type ServiceMapper struct {
 	fetched   bool
 	sidMap    *IDMap
 	slugMap   *IDMap
+	nameMap   *IDMap
 }
 
 func NewServiceMapper(ctx context.Context, client *koyeb.APIClient, appMapper *AppMapper) *ServiceMapper {
@@ -26,6 +27,7 @@ func NewServiceMapper(ctx context.Context, client *koyeb.APIClient, appMapper *A
 		fetched:   false,
 		sidMap:    NewIDMap(),
 		slugMap:   NewIDMap(),
+		nameMap:   NewIDMap(),
 	}
 }
 
@@ -76,6 +78,17 @@ func (mapper *ServiceMapper) GetSlug(id string) (string, error) {
 	return slug, nil
 }
 
+// Identifiers returns the services as <app_name>/<service_name>, to suggest them in shell completions.
+func (mapper *ServiceMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.nameMap.Values(), nil
+}
+
 func (mapper *ServiceMapper) fetch() error {
 	radix := NewRadixTree()
 
@@ -119,6 +132,7 @@ func (mapper *ServiceMapper) fetch() error {
 		}
 
 		mapper.sidMap.Set(service.GetId(), getShortID(service.GetId(), minLength))
+		mapper.nameMap.Set(service.GetId(), fmt.Sprint(appName, "/", service.GetName()))
 
 		// Possible values:
 		// <app_name>/<service_id>
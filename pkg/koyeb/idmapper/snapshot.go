@@ -55,6 +55,17 @@ func (mapper *SnapshotMapper) ResolveID(val string) (string, error) {
 	)
 }
 
+// Identifiers returns the names of the snapshots, to suggest them in shell completions.
+func (mapper *SnapshotMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.nameMap.Values(), nil
+}
+
 func (mapper *SnapshotMapper) fetch() error {
 	radix := NewRadixTree()
 
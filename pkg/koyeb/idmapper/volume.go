@@ -55,6 +55,17 @@ func (mapper *VolumeMapper) ResolveID(val string) (string, error) {
 	)
 }
 
+// Identifiers returns the names of the volumes, to suggest them in shell completions.
+func (mapper *VolumeMapper) Identifiers() ([]string, error) {
+	if !mapper.fetched {
+		err := mapper.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mapper.nameMap.Values(), nil
+}
+
 func (mapper *VolumeMapper) fetch() error {
 	radix := NewRadixTree()
 
@@ -24,28 +24,44 @@ func NewInstanceCmd() *cobra.Command {
 	instanceCmd.AddCommand(listInstanceCmd)
 
 	getInstanceCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get instance",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(instanceHandler.Get),
+		Use:               "get NAME",
+		Short:             "Get instance",
+		ValidArgsFunction: completeArgs(completeInstances),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(instanceHandler.Get),
 	}
 	instanceCmd.AddCommand(getInstanceCmd)
 
 	describeInstanceCmd := &cobra.Command{
-		Use:   "describe NAME",
-		Short: "Describe instance",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(instanceHandler.Describe),
+		Use:               "describe NAME",
+		Short:             "Describe instance",
+		ValidArgsFunction: completeArgs(completeInstances),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(instanceHandler.Describe),
 	}
 	instanceCmd.AddCommand(describeInstanceCmd)
 
 	execInstanceCmd := &cobra.Command{
-		Use:     "exec NAME CMD -- [args...]",
-		Short:   "Run a command in the context of an instance",
-		Aliases: []string{"run", "attach"},
-		Args:    cobra.MinimumNArgs(2),
-		RunE:    WithCLIContext(instanceHandler.Exec),
+		Use:               "exec NAME CMD -- [args...]",
+		Short:             "Run a command in the context of an instance",
+		ValidArgsFunction: completeArgs(completeInstances),
+		Long:              "Run a command in the context of an instance." + execModesHelp,
+		Aliases:           []string{"run", "attach"},
+		Args:              cobra.MinimumNArgs(2),
+		Example: `
+# Open an interactive shell
+$> koyeb instance exec <instance_id> /bin/sh
+
+# Restore a local database dump to a database reachable from the instance
+$> pg_dump mydb | koyeb instance exec <instance_id> -- psql "$DATABASE_URL"
+
+# Download a directory of the instance as a compressed archive
+$> koyeb instance exec <instance_id> -- tar -czf - /data > data.tar.gz
+`,
+		RunE: WithCLIContext(instanceHandler.Exec),
 	}
+	execInstanceCmd.Flags().BoolP("no-tty", "T", false, "Run the command without a TTY, even if the standard input and output are terminals")
+	execInstanceCmd.Flags().String("record", "", "Record the session to this file in the asciicast v2 format, which can be played with koyeb exec replay")
 	instanceCmd.AddCommand(execInstanceCmd)
 
 	cpInstanceCmd := &cobra.Command{
@@ -53,17 +69,74 @@ func NewInstanceCmd() *cobra.Command {
 		Short:   "Copy files and directories to and from instances.",
 		Aliases: []string{"copy"},
 		Args:    cobra.ExactArgs(2),
-		Example: "\nTo copy a file called `hello.txt` from the current directory of your local machine to the `/tmp` directory of a remote Koyeb Instance, type:\n$> koyeb instance cp hello.txt <instance_id>:/tmp/\nTo copy a `spreadsheet.csv` file from the `/tmp/` directory of your Koyeb Instance to the current directory on your local machine, type:\n$> koyeb instance cp <instance_id>:/tmp/spreadsheet.csv .",
+		Example: "\nTo copy a file called `hello.txt` from the current directory of your local machine to the `/tmp` directory of a remote Koyeb Instance, type:\n$> koyeb instance cp hello.txt <instance_id>:/tmp/\nTo copy a `spreadsheet.csv` file from the `/tmp/` directory of your Koyeb Instance to the current directory on your local machine, type:\n$> koyeb instance cp <instance_id>:/tmp/spreadsheet.csv .\nTo copy all the `.log` files of the `/var/log` directory of your Koyeb Instance, compressed, type:\n$> koyeb instance cp --compress '<instance_id>:/var/log/*.log' .\nTo copy a directory to your Koyeb Instance, except the `node_modules` directories, type:\n$> koyeb instance cp --exclude node_modules ./src <instance_id>:/app/",
 		RunE:    WithCLIContext(instanceHandler.Cp),
 	}
+	cpInstanceCmd.Flags().Bool("compress", false, "Compress the transfer with gzip. Single files copied with --compress cannot be resumed if the connection is lost")
+	cpInstanceCmd.Flags().StringSlice("exclude", nil, "Skip the files and directories matching the pattern, using the .gitignore syntax. Can be specified multiple times")
+	cpInstanceCmd.Flags().Bool("preserve-permissions", false, "Keep the owner and the exact permissions of the files, including the setuid, setgid and sticky bits. Otherwise, the permissions of the files are kept but masked by the umask of the destination, and the files belong to the user copying them")
 	instanceCmd.AddCommand(cpInstanceCmd)
 
+	eventsInstanceCmd := &cobra.Command{
+		Use:               "events NAME",
+		Short:             "List the events of an instance and of its deployment, such as status changes, restarts and health check failures",
+		ValidArgsFunction: completeArgs(completeInstances),
+		Args:              cobra.ExactArgs(1),
+		Example: `
+# Display the events of the instance, and the new events until Ctrl+C is pressed
+$> koyeb instance events <instance_id> --watch
+`,
+		RunE: WithCLIContext(instanceHandler.Events),
+	}
+	addEventsFlags(eventsInstanceCmd)
+	instanceCmd.AddCommand(eventsInstanceCmd)
+
+	syncInstanceCmd := &cobra.Command{
+		Use:   "sync SRC DST",
+		Short: "Synchronize a local directory and a directory of an instance, transferring only the files which changed",
+		Args:  cobra.ExactArgs(2),
+		Example: `
+# Synchronize the local directory ./src to the directory /app of the instance, and delete the remote files which do not exist locally
+$> koyeb instance sync --delete ./src <instance_id>:/app
+
+# Synchronize the directory /data of the instance to the local directory ./data
+$> koyeb instance sync <instance_id>:/data ./data
+
+# Display the changes without transferring anything
+$> koyeb instance sync --dry-run ./src <instance_id>:/app
+`,
+		RunE: WithCLIContext(instanceHandler.Sync),
+	}
+	syncInstanceCmd.Flags().Bool("delete", false, "Delete the files of the destination which do not exist in the source")
+	syncInstanceCmd.Flags().StringSlice("exclude", nil, "Skip the files and directories matching the pattern, using the .gitignore syntax. Can be specified multiple times")
+	syncInstanceCmd.Flags().Bool("dry-run", false, "Display the changes without transferring anything")
+	syncInstanceCmd.Flags().Bool("preserve-permissions", false, "Keep the owner and the exact permissions of the files, including the setuid, setgid and sticky bits. Otherwise, the permissions of the files are kept but masked by the umask of the destination, and the files belong to the user synchronizing them")
+	instanceCmd.AddCommand(syncInstanceCmd)
+
+	portForwardInstanceCmd := &cobra.Command{
+		Use:               "port-forward NAME [LOCAL_PORT:]REMOTE_PORT...",
+		Short:             "Forward local ports to an instance",
+		ValidArgsFunction: completeArgs(completeInstances),
+		Args:              cobra.MinimumNArgs(2),
+		Example: `
+# Listen on the local port 8080 and forward the connections to the port 9000 of the instance
+$> koyeb instance port-forward <instance_id> 8080:9000
+
+# Listen on a random local port and forward the connections to the port 5432 of the instance
+$> koyeb instance port-forward <instance_id> :5432
+`,
+		RunE: WithCLIContext(instanceHandler.PortForward),
+	}
+	addPortForwardFlags(portForwardInstanceCmd)
+	instanceCmd.AddCommand(portForwardInstanceCmd)
+
 	var since dates.HumanFriendlyDate
 	logInstanceCmd := &cobra.Command{
-		Use:     "logs NAME",
-		Aliases: []string{"l", "log"},
-		Short:   "Get instance logs",
-		Args:    cobra.ExactArgs(1),
+		Use:               "logs NAME",
+		Aliases:           []string{"l", "log"},
+		Short:             "Get instance logs",
+		ValidArgsFunction: completeArgs(completeInstances),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			return instanceHandler.Logs(ctx, cmd, since.Time, args)
 		}),
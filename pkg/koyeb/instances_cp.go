@@ -49,7 +49,12 @@ func (h *InstanceHandler) Cp(ctx *CLIContext, cmd *cobra.Command, args []string)
 		return err
 	}
 
-	manager, err := NewCopyManager(srcSpec, dstSpec)
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+	manager, err := NewCopyManager(srcSpec, dstSpec, CopyOptions{
+		Compress:            GetBoolFlags(cmd, "compress"),
+		Exclude:             exclude,
+		PreservePermissions: GetBoolFlags(cmd, "preserve-permissions"),
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,52 @@
+package koyeb
+
+import (
+	"fmt"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/spf13/cobra"
+)
+
+// Events displays the events of the instance, such as its status changes, and
+// the events of its deployment.
+func (h *InstanceHandler) Events(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	instanceID, err := h.ResolveInstanceArgs(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	res, resp, err := ctx.Client.InstancesApi.GetInstance(ctx.Context, instanceID).Execute()
+	if err != nil {
+		return errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while retrieving the instance `%s`", args[0]),
+			err,
+			resp,
+		)
+	}
+
+	// Instances reference their regional deployment, which belongs to the deployment
+	deploymentID := ""
+	if regionalDeploymentID := res.Instance.GetRegionalDeploymentId(); regionalDeploymentID != "" {
+		regionalRes, resp, err := ctx.Client.RegionalDeploymentsApi.GetRegionalDeployment(ctx.Context, regionalDeploymentID).Execute()
+		if err != nil {
+			return errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while retrieving the deployment of the instance `%s`", args[0]),
+				err,
+				resp,
+			)
+		}
+		deploymentID = regionalRes.RegionalDeployment.GetDeploymentId()
+	}
+
+	return renderEvents(ctx, cmd, func(limit int) ([]resourceEvent, error) {
+		events, err := listInstanceEvents(ctx, []string{instanceID}, limit)
+		if err != nil {
+			return nil, err
+		}
+		deploymentEvents, err := listDeploymentEvents(ctx, deploymentID, limit)
+		if err != nil {
+			return nil, err
+		}
+		return append(events, deploymentEvents...), nil
+	})
+}
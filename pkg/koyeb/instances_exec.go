@@ -1,6 +1,7 @@
 package koyeb
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -15,10 +16,16 @@ func (h *InstanceHandler) Exec(ctx *CLIContext, cmd *cobra.Command, args []strin
 		return err
 	}
 
+	recorder, err := newSessionRecorderFromFlags(cmd, fmt.Sprintf("koyeb instance exec %s", args[0]), args[1:])
+	if err != nil {
+		return err
+	}
+
 	returnCode, err := ctx.ExecClient.Exec(ctx.Context, ExecId{
 		Id:   instance,
 		Type: koyeb.EXECCOMMANDREQUESTIDTYPE_INSTANCE_ID,
-	}, args[1:])
+	}, args[1:], ExecOptions{Recorder: recorder, NoTTY: GetBoolFlags(cmd, "no-tty")})
+	closeSessionRecorder(recorder)
 	if err != nil {
 		return &errors.CLIError{
 			What:       "Error while executing the command",
@@ -28,6 +35,9 @@ func (h *InstanceHandler) Exec(ctx *CLIContext, cmd *cobra.Command, args []strin
 			Solution:   "Make sure the command is correct and exists in the service. If the problem persists, try to update the CLI to the latest version.",
 		}
 	}
+	if returnCode < 0 {
+		returnCode = ExecExitCodeConnectionClosed
+	}
 	if returnCode != 0 {
 		os.Exit(returnCode)
 	}
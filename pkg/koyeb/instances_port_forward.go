@@ -0,0 +1,18 @@
+package koyeb
+
+import (
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/spf13/cobra"
+)
+
+func (h *InstanceHandler) PortForward(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	instance, err := h.ResolveInstanceArgs(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	return PortForward(ctx, cmd, ExecId{
+		Id:   instance,
+		Type: koyeb.EXECCOMMANDREQUESTIDTYPE_INSTANCE_ID,
+	}, args[1:])
+}
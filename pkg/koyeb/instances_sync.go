@@ -0,0 +1,63 @@
+package koyeb
+
+import (
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func (h *InstanceHandler) Sync(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	src, dst := args[0], args[1]
+
+	srcSpec, err := h.ExtractFileSpec(ctx, src)
+	if err != nil {
+		return err
+	}
+	dstSpec, err := h.ExtractFileSpec(ctx, dst)
+	if err != nil {
+		return err
+	}
+	if hasGlob(srcSpec.FilePath) {
+		return &errors.CLIError{
+			What:       "Error while synchronizing",
+			Why:        "Glob patterns are not supported",
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Set the source directory, and skip files with --exclude",
+		}
+	}
+
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+	dryRun := GetBoolFlags(cmd, "dry-run")
+	manager, err := NewSyncManager(srcSpec, dstSpec, SyncOptions{
+		Delete:              GetBoolFlags(cmd, "delete"),
+		Exclude:             exclude,
+		DryRun:              dryRun,
+		PreservePermissions: GetBoolFlags(cmd, "preserve-permissions"),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Synchronizing %s to %s ...", src, dst)
+	changes, err := manager.Sync(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx.Renderer.Render(&SyncReply{changes: changes})
+
+	counts := map[string]int{}
+	for _, change := range changes {
+		counts[change.Change]++
+	}
+	switch {
+	case len(changes) == 0:
+		log.Infof("%s is already up to date", dst)
+	case dryRun:
+		log.Infof("Dry run: %d files would be added, %d modified and %d deleted", counts["added"], counts["modified"], counts["deleted"])
+	default:
+		log.Infof("%d files added, %d modified and %d deleted", counts["added"], counts["modified"], counts["deleted"])
+	}
+	return nil
+}
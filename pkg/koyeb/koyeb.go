@@ -53,9 +53,16 @@ func isHelpCalled(rootCmd *cobra.Command) bool {
 	return false
 }
 
+// isExecReplayCalled returns true for `koyeb exec replay`, which plays local
+// recordings and does not need the configuration.
+func isExecReplayCalled(rootCmd *cobra.Command) bool {
+	replayCmd, _, err := rootCmd.Find([]string{"exec", "replay"})
+	return err == nil && replayCmd.Name() == "replay" && replayCmd.CalledAs() != ""
+}
+
 func skipConfigLoading(rootCmd *cobra.Command) bool {
 	return loginCmd.CalledAs() != "" || versionCmd.CalledAs() != "" ||
-		completionCmd.CalledAs() != "" || isHelpCalled(rootCmd)
+		completionCmd.CalledAs() != "" || isHelpCalled(rootCmd) || isExecReplayCalled(rootCmd)
 }
 
 func GetRootCommand() *cobra.Command {
@@ -75,7 +82,10 @@ func GetRootCommand() *cobra.Command {
 			if err := initConfig(cmd.Root()); err != nil {
 				return err
 			}
-			DetectUpdates()
+			// Completion requests must not print anything else than the completions
+			if cmd.Name() != cobra.ShellCompRequestCmd {
+				DetectUpdates()
+			}
 			return SetupCLIContext(cmd, organization)
 		},
 	}
@@ -83,7 +93,7 @@ func GetRootCommand() *cobra.Command {
 	log.SetFormatter(&log.TextFormatter{})
 
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is $HOME/.koyeb.yaml, or $KOYEB_CONFIG if set)")
-	rootCmd.PersistentFlags().VarP(&outputFormat, "output", "o", "output format (yaml,json,table)")
+	rootCmd.PersistentFlags().VarP(&outputFormat, "output", "o", "output format (yaml,json,table,definition)")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "enable the debug output")
 	rootCmd.PersistentFlags().BoolVar(&debugFull, "debug-full", false, "do not hide sensitive information (tokens) in the debug output")
 	rootCmd.PersistentFlags().BoolVar(&forceASCII, "force-ascii", false, "only output ascii characters (no unicode emojis)")
@@ -117,8 +127,14 @@ func GetRootCommand() *cobra.Command {
 	rootCmd.AddCommand(NewVolumeCmd())
 	rootCmd.AddCommand(NewSnapshotCmd())
 	rootCmd.AddCommand(NewComposeCmd())
+	rootCmd.AddCommand(NewPreviewCmd())
 	rootCmd.AddCommand(NewSandboxCmd())
 	rootCmd.AddCommand(NewWhoAmICmd())
+	rootCmd.AddCommand(NewWaitCmd())
+	rootCmd.AddCommand(NewExecCmd())
+	rootCmd.AddCommand(NewUICmd())
+
+	registerFlagCompletions(rootCmd)
 	return rootCmd
 }
 
@@ -170,6 +186,15 @@ func Run() error {
 	return err
 }
 
+// ExitCode returns the exit code of the CLI for the error returned by Run.
+func ExitCode(err error) int {
+	var cliErr *koyeb_errors.CLIError
+	if errors.As(err, &cliErr) {
+		return cliErr.GetExitCode()
+	}
+	return 1
+}
+
 func PrintVersion(cmd *cobra.Command, args []string) {
 	fmt.Printf("%s\n", Version)
 	log.Debugf("Date: %s", BuildDate)
@@ -221,7 +221,7 @@ func (client *LogsAPIClient) NewWatchLogsQuery(
 	}
 
 	query.header = http.Header{
-		"Sec-Websocket-Protocol": []string{fmt.Sprintf("Bearer, %s", token)},
+		"Sec-Websocket-Protocol": []string{fmt.Sprintf("Bearer, %s", client.token)},
 	}
 
 	switch logType {
@@ -406,6 +406,17 @@ func (query *WatchLogsQuery) Execute(ctx context.Context) (chan WatchLogsEntry,
 			}()
 
 			select {
+			case <-ctx.Done():
+				// The caller is no longer interested in the logs: close the
+				// connection and wait for the reader goroutine to return.
+				conn.Stop()
+				conn.Conn.Close()
+				select {
+				case <-readCh:
+				case <-errCh:
+				}
+				close(logs)
+				return
 			case <-timer.C:
 				// Stop sending ping messages to the websocket connection
 				conn.Stop()
@@ -0,0 +1,135 @@
+package koyeb
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	log "github.com/sirupsen/logrus"
+)
+
+// Duration to wait before stopping to print the logs of a deployment which
+// reached a final status, to give a chance to the last lines to be received.
+const logsFollowerDrainDelay = 2 * time.Second
+
+// Statuses of a deployment for which the build is over and the instances are running or starting.
+var deploymentRuntimeStatuses = []koyeb.DeploymentStatus{
+	koyeb.DEPLOYMENTSTATUS_ALLOCATING,
+	koyeb.DEPLOYMENTSTATUS_STARTING,
+	koyeb.DEPLOYMENTSTATUS_HEALTHY,
+	koyeb.DEPLOYMENTSTATUS_DEGRADED,
+	koyeb.DEPLOYMENTSTATUS_UNHEALTHY,
+	koyeb.DEPLOYMENTSTATUS_STOPPING,
+	koyeb.DEPLOYMENTSTATUS_STOPPED,
+	koyeb.DEPLOYMENTSTATUS_ERRORING,
+}
+
+// Statuses of a deployment which is building or starting. The logs of these
+// deployments are not drained when the follower stops, and koyeb deploy --watch
+// cancels them when a newer change is deployed.
+var deploymentInProgressStatuses = []koyeb.DeploymentStatus{
+	koyeb.DEPLOYMENTSTATUS_PENDING,
+	koyeb.DEPLOYMENTSTATUS_PROVISIONING,
+	koyeb.DEPLOYMENTSTATUS_SCHEDULED,
+	koyeb.DEPLOYMENTSTATUS_ALLOCATING,
+	koyeb.DEPLOYMENTSTATUS_STARTING,
+}
+
+// DeploymentLogsFollower prints the build logs of a deployment, then its
+// runtime logs once the build is over. It is used by the commands accepting
+// --wait --logs, which call Stop when the deployment reaches a final status.
+type DeploymentLogsFollower struct {
+	ctx          *CLIContext
+	deploymentID string
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// FollowDeploymentLogs starts printing the logs of the deployment in the background.
+func FollowDeploymentLogs(ctx *CLIContext, deploymentID string, full bool) *DeploymentLogsFollower {
+	ctxf, cancel := context.WithCancel(ctx.Context)
+	f := &DeploymentLogsFollower{ctx: ctx, deploymentID: deploymentID, cancel: cancel}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		stopBuildLogs := f.tail(ctx, ctxf, deploymentID, "build", full)
+		for range ticker(ctxf, 2*time.Second) {
+			res, _, err := ctx.Client.DeploymentsApi.GetDeployment(ctxf, deploymentID).Execute()
+			if err != nil {
+				// Errors are reported by the wait loop of the caller
+				continue
+			}
+			if slices.Contains(deploymentRuntimeStatuses, res.Deployment.GetStatus()) {
+				stopBuildLogs()
+				f.tail(ctx, ctxf, deploymentID, "runtime", full)
+				return
+			}
+		}
+	}()
+	return f
+}
+
+// tail prints the logs of the given type until ctxf is canceled, or until the returned function is called.
+func (f *DeploymentLogsFollower) tail(ctx *CLIContext, ctxf context.Context, deploymentID string, logsType string, full bool) context.CancelFunc {
+	ctxt, cancel := context.WithCancel(ctxf)
+
+	// PrintLogs uses the context of the CLIContext, so we give it a copy
+	// with a context we can cancel.
+	tailCtx := *ctx
+	tailCtx.Context = ctxt
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		err := ctx.LogsClient.PrintLogs(&tailCtx, LogsQuery{
+			Type:         logsType,
+			DeploymentId: deploymentID,
+			Tail:         true,
+			Order:        "asc",
+			Full:         full,
+		})
+		// Errors caused by the cancellation of the context are expected
+		if err != nil && ctxt.Err() == nil {
+			log.Errorf("Error while getting the %s logs: %s", logsType, err)
+		}
+	}()
+	return cancel
+}
+
+// Stop stops printing the logs, and returns once all the goroutines have
+// exited. If the deployment reached a final status, the last lines are given
+// logsFollowerDrainDelay to be received. Otherwise, for example after a
+// timeout, an error or Ctrl+C, the logs are stopped immediately.
+func (f *DeploymentLogsFollower) Stop() {
+	if f.deploymentFinished() {
+		time.Sleep(logsFollowerDrainDelay)
+	}
+	f.Cancel()
+}
+
+// Cancel stops printing the logs immediately, and returns once all the
+// goroutines have exited.
+func (f *DeploymentLogsFollower) Cancel() {
+	f.cancel()
+	f.wg.Wait()
+}
+
+// deploymentFinished returns true if the deployment is no longer building nor
+// starting.
+func (f *DeploymentLogsFollower) deploymentFinished() bool {
+	if f.ctx.Context.Err() != nil {
+		return false
+	}
+	ctxd, cancel := context.WithTimeout(f.ctx.Context, logsFollowerDrainDelay)
+	defer cancel()
+
+	res, _, err := f.ctx.Client.DeploymentsApi.GetDeployment(ctxd, f.deploymentID).Execute()
+	if err != nil {
+		return false
+	}
+	return !slices.Contains(deploymentInProgressStatuses, res.Deployment.GetStatus())
+}
@@ -0,0 +1,188 @@
+package koyeb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// remoteHostRegexp validates --remote-host, which is interpolated in the relay
+// command executed in the instance.
+var remoteHostRegexp = regexp.MustCompile(`^[A-Za-z0-9.:-]+$`)
+
+// portForwardSpec is a port to forward, set with [LOCAL_PORT:]REMOTE_PORT. A
+// local port of 0 means a random port is picked.
+type portForwardSpec struct {
+	local  int
+	remote int
+}
+
+// parsePortForwardSpecs parses the ports to forward. The accepted formats are
+// LOCAL_PORT:REMOTE_PORT, PORT to listen locally on the same port, and
+// :REMOTE_PORT to listen on a random local port.
+func parsePortForwardSpecs(values []string) ([]portForwardSpec, error) {
+	ret := []portForwardSpec{}
+
+	for _, value := range values {
+		local, remote, found := strings.Cut(value, ":")
+		if !found {
+			remote = value
+			local = value
+		} else if local == "" {
+			local = "0"
+		}
+
+		localPort, localErr := strconv.Atoi(local)
+		remotePort, remoteErr := strconv.Atoi(remote)
+		if localErr != nil || remoteErr != nil || localPort < 0 || localPort > 65535 || remotePort < 1 || remotePort > 65535 {
+			return nil, &errors.CLIError{
+				What:       "Error while parsing the ports to forward",
+				Why:        fmt.Sprintf("unable to parse the port `%s`", value),
+				Additional: []string{"The expected format is [LOCAL_PORT:]REMOTE_PORT, for example 8080:9000, 9000 or :9000 to pick a random local port"},
+				Orig:       nil,
+				Solution:   "Fix the ports and try again",
+			}
+		}
+		ret = append(ret, portForwardSpec{local: localPort, remote: remotePort})
+	}
+	return ret, nil
+}
+
+// portForwardRelayCommand returns the command executed in the instance for each
+// forwarded connection. It relays its standard input and output to the remote
+// port with the first tool available in the container.
+func portForwardRelayCommand(host string, port int) []string {
+	script := fmt.Sprintf(`if command -v socat >/dev/null 2>&1; then exec socat - TCP:%[1]s:%[2]d
+elif command -v nc >/dev/null 2>&1; then exec nc %[1]s %[2]d
+elif command -v bash >/dev/null 2>&1; then exec bash -c 'exec 3<>/dev/tcp/%[1]s/%[2]d; cat <&3 & cat >&3; wait'
+else echo "port-forward: socat, nc or bash is required in the instance to forward ports" >&2; exit 127
+fi`, host, port)
+	return []string{"sh", "-c", script}
+}
+
+// addPortForwardFlags adds the flags shared by instance port-forward and service port-forward.
+func addPortForwardFlags(cmd *cobra.Command) {
+	cmd.Flags().String("address", "127.0.0.1", "Local address to listen on")
+	cmd.Flags().String("remote-host", "127.0.0.1", "Host to connect to from the instance")
+}
+
+// PortForward listens on the local ports, and tunnels each connection through
+// the exec API to the remote port of the instance, until the user presses
+// Ctrl+C.
+func PortForward(ctx *CLIContext, cmd *cobra.Command, id ExecId, ports []string) error {
+	specs, err := parsePortForwardSpecs(ports)
+	if err != nil {
+		return err
+	}
+
+	address := GetStringFlags(cmd, "address")
+	remoteHost := GetStringFlags(cmd, "remote-host")
+	if !remoteHostRegexp.MatchString(remoteHost) {
+		return &errors.CLIError{
+			What:       "Error while forwarding ports",
+			Why:        fmt.Sprintf("the remote host `%s` is invalid", remoteHost),
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Set a hostname or an IP address with --remote-host",
+		}
+	}
+
+	forwardCtx, cancel := context.WithCancel(ctx.Context)
+	defer cancel()
+
+	listeners := []net.Listener{}
+	defer func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}()
+	for _, spec := range specs {
+		listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(spec.local)))
+		if err != nil {
+			return &errors.CLIError{
+				What:       "Error while forwarding ports",
+				Why:        fmt.Sprintf("unable to listen on %s", net.JoinHostPort(address, strconv.Itoa(spec.local))),
+				Additional: nil,
+				Orig:       err,
+				Solution:   "Make sure the local port is not already in use, or pick another local port",
+			}
+		}
+		listeners = append(listeners, listener)
+		log.Infof("Forwarding from %s -> %d", listener.Addr(), spec.remote)
+	}
+
+	var wg sync.WaitGroup
+	for idx, listener := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			acceptPortForwardConnections(forwardCtx, ctx.ExecClient, &wg, listener, id, portForwardRelayCommand(remoteHost, specs[idx].remote))
+		}()
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	select {
+	case <-sigs:
+	case <-forwardCtx.Done():
+	}
+
+	// Stop accepting new connections, and close the connections in progress
+	cancel()
+	for _, listener := range listeners {
+		listener.Close()
+	}
+	wg.Wait()
+	return nil
+}
+
+// acceptPortForwardConnections accepts the connections of listener until it is
+// closed, and relays each of them in its own exec session.
+func acceptPortForwardConnections(ctx context.Context, client *ExecAPIClient, wg *sync.WaitGroup, listener net.Listener, id ExecId, relay []string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Errorf("Error while accepting connections on %s: %s", listener.Addr(), err)
+			}
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+
+			log.Infof("Handling connection from %s", conn.RemoteAddr())
+
+			// Close the connection when the forwarding stops, to unblock the
+			// read of the connection
+			connCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go func() {
+				<-connCtx.Done()
+				conn.Close()
+			}()
+
+			code, err := client.ExecWithStreams(connCtx, &StdStreams{Stdin: conn, Stdout: conn, Stderr: os.Stderr}, id, relay)
+			if err != nil && ctx.Err() == nil {
+				log.Warnf("Error while forwarding the connection from %s: %s", conn.RemoteAddr(), err)
+			} else if code > 0 {
+				log.Warnf("The relay of the connection from %s exited with code %d", conn.RemoteAddr(), code)
+			}
+		}()
+	}
+}
@@ -0,0 +1,42 @@
+package koyeb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePortForwardSpecs(t *testing.T) {
+	tests := map[string]struct {
+		values   []string
+		expected []portForwardSpec
+		err      string
+	}{
+		"local_and_remote": {values: []string{"8080:9000"}, expected: []portForwardSpec{{local: 8080, remote: 9000}}},
+		"same_port":        {values: []string{"9000"}, expected: []portForwardSpec{{local: 9000, remote: 9000}}},
+		"random_local":     {values: []string{":9000"}, expected: []portForwardSpec{{local: 0, remote: 9000}}},
+		"multiple": {
+			values:   []string{"8080:80", "5432"},
+			expected: []portForwardSpec{{local: 8080, remote: 80}, {local: 5432, remote: 5432}},
+		},
+		"none":           {values: []string{}, expected: []portForwardSpec{}},
+		"not_a_number":   {values: []string{"http"}, err: "unable to parse the port `http`"},
+		"empty_remote":   {values: []string{"8080:"}, err: "unable to parse the port `8080:`"},
+		"remote_zero":    {values: []string{"8080:0"}, err: "unable to parse the port `8080:0`"},
+		"local_too_high": {values: []string{"70000:80"}, err: "unable to parse the port `70000:80`"},
+		"negative_local": {values: []string{"-1:80"}, err: "unable to parse the port `-1:80`"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			specs, err := parsePortForwardSpecs(tc.values)
+			if tc.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, specs)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package koyeb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func NewPreviewCmd() *cobra.Command {
+	h := NewPreviewHandler()
+
+	previewCmd := &cobra.Command{
+		Use:     "preview ACTION",
+		Aliases: []string{"previews"},
+		Short:   "Manage preview environments",
+		Long: `Manage preview environments.
+
+A preview environment is a copy of all the services of an application, deployed from another git branch. It is created in the application <app>-pr-<branch>, and is deleted after a period of inactivity.`,
+	}
+
+	addPreviewFlags := func(flags *pflag.FlagSet) {
+		flags.StringP("app", "a", "", "Application to preview")
+		flags.StringP("branch", "b", "", "Git branch to deploy")
+		flags.String("name", "", "Name of the preview application. Defaults to <app>-pr-<branch>")
+	}
+
+	createPreviewCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a preview environment",
+		Long: `Create a preview environment: the services of the application are cloned into a new application, and the services deployed from git are deployed from the branch given with --branch.
+
+The services of the preview are deleted after the duration given with --delete-after-inactivity-delay once they are sleeping, and the application is deleted when its last service is deleted. To let them sleep, the minimum scale of the web services of the preview is set to 0. Worker services never sleep: delete them with koyeb preview delete.`,
+		Args: cobra.NoArgs,
+		Example: `
+# Deploy the branch feature-x of the services of the application myapp in the application myapp-pr-feature-x
+$> koyeb preview create --app myapp --branch feature-x
+`,
+		RunE: WithCLIContext(h.Create),
+	}
+	addPreviewFlags(createPreviewCmd.Flags())
+	addCloneFlags(createPreviewCmd.Flags())
+	createPreviewCmd.Flags().Duration("delete-after-inactivity-delay", 24*time.Hour, "Delete the services of the preview after being sleeping for this duration. The web services of the preview scale to zero so they can sleep. Set to 0 to keep them and their minimum scale")
+	_ = createPreviewCmd.MarkFlagRequired("app")
+	_ = createPreviewCmd.MarkFlagRequired("branch")
+	previewCmd.AddCommand(createPreviewCmd)
+
+	deletePreviewCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a preview environment",
+		Args:  cobra.NoArgs,
+		Example: `
+# Delete the preview environment of the branch feature-x of the application myapp
+$> koyeb preview delete --app myapp --branch feature-x
+`,
+		RunE: WithCLIContext(h.Delete),
+	}
+	addPreviewFlags(deletePreviewCmd.Flags())
+	previewCmd.AddCommand(deletePreviewCmd)
+
+	return previewCmd
+}
+
+func NewPreviewHandler() *PreviewHandler {
+	return &PreviewHandler{}
+}
+
+type PreviewHandler struct {
+}
+
+var previewNameInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// previewAppName returns the name of the application of the preview: the
+// --name flag if set, or <app>-pr-<branch>. The branch is converted to a valid
+// application name, for example feature/Login becomes feature-login.
+func previewAppName(cmd *cobra.Command) (string, error) {
+	if name := GetStringFlags(cmd, "name"); name != "" {
+		return name, nil
+	}
+
+	app := GetStringFlags(cmd, "app")
+	branch := previewNameInvalidChars.ReplaceAllString(strings.ToLower(GetStringFlags(cmd, "branch")), "-")
+	branch = strings.Trim(branch, "-")
+	if app == "" || branch == "" {
+		return "", &errors.CLIError{
+			What:       "Error while computing the name of the preview",
+			Why:        "the application and the branch are required",
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Set --app and --branch, or the name of the preview with --name",
+		}
+	}
+	return fmt.Sprintf("%s-pr-%s", app, branch), nil
+}
@@ -0,0 +1,110 @@
+package koyeb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/renderer"
+	"github.com/spf13/cobra"
+)
+
+func (h *PreviewHandler) Create(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	name, err := previewAppName(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts, err := parseCloneOptions(cmd.Flags())
+	if err != nil {
+		return err
+	}
+	opts.gitBranch = GetStringFlags(cmd, "branch")
+	// Delete the application once the services have been deleted for inactivity
+	opts.appLifeCycle = koyeb.NewAppLifeCycleWithDefaults()
+	opts.appLifeCycle.SetDeleteWhenEmpty(true)
+	opts.serviceLifeCycle = koyeb.NewServiceLifeCycleWithDefaults()
+	if delay := GetDurationFlags(cmd, "delete-after-inactivity-delay"); delay > 0 {
+		opts.serviceLifeCycle.SetDeleteAfterSleep(int64(delay.Seconds()))
+		// The services are only deleted once they sleep, which requires them to scale to zero
+		opts.scaleToZero = true
+	}
+
+	appHandler := NewAppHandler()
+	reply, appID, err := appHandler.cloneApp(ctx, GetStringFlags(cmd, "app"), name, opts, map[string]string{})
+	if err != nil {
+		// Display the services created before the error
+		if reply != nil {
+			ctx.Renderer.Render(reply)
+		}
+		return err
+	}
+	if opts.dryRun {
+		ctx.Renderer.Render(reply)
+		return nil
+	}
+
+	urls, err := h.getPublicURLs(ctx, appID, name)
+	if err != nil {
+		return err
+	}
+	renderer.NewChainRenderer(ctx.Renderer).Render(reply).Render(urls)
+	return nil
+}
+
+// getPublicURLs returns the URLs of the routes of the services of the application.
+func (h *PreviewHandler) getPublicURLs(ctx *CLIContext, appID string, appName string) (*PreviewURLsReply, error) {
+	resApp, resp, err := ctx.Client.AppsApi.GetApp(ctx.Context, appID).Execute()
+	if err != nil {
+		return nil, errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while retrieving the application `%s`", appName),
+			err,
+			resp,
+		)
+	}
+
+	services, err := listAppServices(ctx, appID, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceHandler := NewServiceHandler()
+	reply := &PreviewURLsReply{items: []map[string]string{}}
+	for _, service := range services {
+		serviceName := fmt.Sprintf("%s/%s", appName, service.GetName())
+		latestDeploy, err := serviceHandler.getLatestDeployment(ctx, service.GetId(), serviceName)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range latestDeploy.GetDefinition().Routes {
+			for _, domain := range resApp.App.GetDomains() {
+				reply.items = append(reply.items, map[string]string{
+					"service": serviceName,
+					"url":     fmt.Sprintf("https://%s%s", domain.GetName(), route.GetPath()),
+				})
+			}
+		}
+	}
+	return reply, nil
+}
+
+type PreviewURLsReply struct {
+	items []map[string]string
+}
+
+func (PreviewURLsReply) Title() string {
+	return "Public URLs"
+}
+
+func (r *PreviewURLsReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.items)
+}
+
+func (r *PreviewURLsReply) Headers() []string {
+	return []string{"service", "url"}
+}
+
+func (r *PreviewURLsReply) Fields() []map[string]string {
+	return r.items
+}
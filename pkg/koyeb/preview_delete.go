@@ -0,0 +1,14 @@
+package koyeb
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func (h *PreviewHandler) Delete(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	name, err := previewAppName(cmd)
+	if err != nil {
+		return err
+	}
+	// Deleting the application deletes its services
+	return NewAppHandler().Delete(ctx, cmd, []string{name})
+}
@@ -0,0 +1,153 @@
+package koyeb
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	progressBarWidth           = 30
+	progressBarRefreshInterval = 200 * time.Millisecond
+)
+
+// progressBar displays the progress of a transfer on stderr, with the
+// throughput and the estimated remaining time:
+//
+//	Uploading archive  42% [============>                 ] 12.3 MiB / 29.1 MiB  4.2 MiB/s  ETA 4s
+//
+// When stderr is not a terminal, nothing is displayed. All the methods can be
+// called on a nil *progressBar.
+type progressBar struct {
+	label   string
+	total   int64
+	out     io.Writer
+	started time.Time
+
+	mu       sync.Mutex
+	current  int64
+	rendered time.Time
+}
+
+// newProgressBar returns a progress bar for a transfer of total bytes, or nil
+// if stderr is not a terminal. If total is unknown, set it to 0.
+func newProgressBar(label string, total int64) *progressBar {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return &progressBar{label: label, total: total, out: os.Stderr, started: time.Now()}
+}
+
+// Add records that n more bytes have been transferred.
+func (p *progressBar) Add(n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current += n
+	if time.Since(p.rendered) >= progressBarRefreshInterval {
+		p.render()
+	}
+}
+
+// Set records that n bytes have been transferred in total, for example when a
+// transfer is resumed from an offset.
+func (p *progressBar) Set(n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current = n
+	p.render()
+}
+
+// Finish displays the final state of the progress bar and ends the line.
+func (p *progressBar) Finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.render()
+	fmt.Fprintln(p.out)
+}
+
+// Reader wraps r to record the bytes read.
+func (p *progressBar) Reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	return &progressReader{r, p}
+}
+
+// Writer wraps w to record the bytes written.
+func (p *progressBar) Writer(w io.Writer) io.Writer {
+	if p == nil {
+		return w
+	}
+	return &progressWriter{w, p}
+}
+
+func (p *progressBar) render() {
+	p.rendered = time.Now()
+	elapsed := time.Since(p.started).Seconds()
+
+	var throughput float64
+	if elapsed > 0 {
+		throughput = float64(p.current) / elapsed
+	}
+
+	var line string
+	if p.total > 0 {
+		ratio := min(float64(p.current)/float64(p.total), 1)
+		filled := int(ratio * progressBarWidth)
+		bar := strings.Repeat("=", filled)
+		if filled < progressBarWidth {
+			bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+		}
+
+		eta := "-"
+		if throughput > 0 {
+			remaining := time.Duration(float64(p.total-p.current)/throughput) * time.Second
+			eta = remaining.Round(time.Second).String()
+		}
+		line = fmt.Sprintf("%s %3d%% [%s] %s / %s  %s/s  ETA %s",
+			p.label, int(ratio*100), bar, formatBytes(p.current), formatBytes(p.total), formatBytes(int64(throughput)), eta)
+	} else {
+		line = fmt.Sprintf("%s %s  %s/s", p.label, formatBytes(p.current), formatBytes(int64(throughput)))
+	}
+	// \r moves the cursor to the beginning of the line, \033[K clears the end of the line
+	fmt.Fprintf(p.out, "\r%s\033[K", line)
+}
+
+type progressReader struct {
+	io.Reader
+	progress *progressBar
+}
+
+func (r *progressReader) Read(buf []byte) (int, error) {
+	n, err := r.Reader.Read(buf)
+	r.progress.Add(int64(n))
+	return n, err
+}
+
+type progressWriter struct {
+	io.Writer
+	progress *progressBar
+}
+
+func (w *progressWriter) Write(buf []byte) (int, error) {
+	n, err := w.Writer.Write(buf)
+	w.progress.Add(int64(n))
+	return n, err
+}
@@ -22,10 +22,11 @@ func NewRegionalDeploymentCmd() *cobra.Command {
 	regionalDeploymentCmd.AddCommand(listRegionalDeploymentCmd)
 
 	getRegionalDeploymentCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get regional deployment",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Get),
+		Use:               "get NAME",
+		Short:             "Get regional deployment",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeRegionalDeployments),
+		RunE:              WithCLIContext(h.Get),
 	}
 	regionalDeploymentCmd.AddCommand(getRegionalDeploymentCmd)
 
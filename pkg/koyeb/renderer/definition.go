@@ -0,0 +1,40 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// DefinitionResource is implemented by the resources which can be exported as
+// a deployment definition, i.e. the format accepted by `koyeb service create
+// --file` and `koyeb service update --file`.
+type DefinitionResource interface {
+	MarshalDefinition() ([]byte, error)
+}
+
+// DefinitionRenderer displays the deployment definition of the resource as
+// YAML. Resources which do not have a definition are rendered as YAML.
+type DefinitionRenderer struct{}
+
+func (r *DefinitionRenderer) Render(item ApiResources) {
+	definer, ok := item.(DefinitionResource)
+	if !ok {
+		(&YAMLRenderer{}).Render(item)
+		return
+	}
+	buf, err := definer.MarshalDefinition()
+	// Should never happen, since all the fields of the definition are marshable
+	if err != nil {
+		panic("Unable to marshal definition")
+	}
+	y, err := yaml.JSONToYAML(buf)
+	if err != nil {
+		panic("Unable to convert JSON to YAML")
+	}
+	fmt.Printf("%s", string(y))
+}
+
+func (r *DefinitionRenderer) RenderSeparator() {
+	fmt.Println("---")
+}
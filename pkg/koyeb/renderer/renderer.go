@@ -1,7 +1,8 @@
 // Package renderer provides a set of renderers to display API resources.
 //
 // The default TableRenderer displays the resources as a table. The JSONRenderer
-// and YAMLRenderer display the resources as JSON and YAML respectively.
+// and YAMLRenderer display the resources as JSON and YAML respectively. The
+// DefinitionRenderer displays the deployment definition of the resource.
 // ChainRenderer can be used to display multiple resources.
 //
 // The resource to display must implement the ApiResources interface.
@@ -30,6 +31,9 @@ const (
 	JSONFormat  OutputFormat = "json"
 	YAMLFormat  OutputFormat = "yaml"
 	TableFormat OutputFormat = "table"
+	// DefinitionFormat renders the deployment definition of the resource, in
+	// the format accepted by `koyeb service create --file`.
+	DefinitionFormat OutputFormat = "definition"
 )
 
 func (f *OutputFormat) String() string {
@@ -38,11 +42,11 @@ func (f *OutputFormat) String() string {
 
 func (f *OutputFormat) Set(value string) error {
 	switch value {
-	case "json", "yaml", "table":
+	case "json", "yaml", "table", "definition":
 		*f = OutputFormat(value)
 		return nil
 	}
-	return errors.New(`invalid output format. Valid values are "json", "yaml" and "table"`)
+	return errors.New(`invalid output format. Valid values are "json", "yaml", "table" and "definition"`)
 }
 
 func (f *OutputFormat) Type() string {
@@ -55,6 +59,8 @@ func NewRenderer(format OutputFormat) Renderer {
 		return &JSONRenderer{}
 	case YAMLFormat:
 		return &YAMLRenderer{}
+	case DefinitionFormat:
+		return &DefinitionRenderer{}
 	default:
 		return &TableRenderer{}
 	}
@@ -72,9 +72,10 @@ $> koyeb sandbox create myapp/mysandbox --wait
 
 	// run command - execute a command synchronously
 	runSandboxCmd := &cobra.Command{
-		Use:   "run NAME COMMAND [ARGS...]",
-		Short: "Execute a command in the sandbox",
-		Args:  cobra.MinimumNArgs(2),
+		Use:               "run NAME COMMAND [ARGS...]",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Execute a command in the sandbox",
+		Args:              cobra.MinimumNArgs(2),
 		Example: `
 # Run a simple command
 $> koyeb sandbox run myapp/mysandbox echo "Hello World"
@@ -104,10 +105,11 @@ $> koyeb sandbox run myapp/mysandbox --timeout 120 long-running-command
 
 	// start command - start a background process
 	startProcessCmd := &cobra.Command{
-		Use:     "start NAME COMMAND [ARGS...]",
-		Short:   "Start a background process in the sandbox",
-		Aliases: []string{"launch"},
-		Args:    cobra.MinimumNArgs(2),
+		Use:               "start NAME COMMAND [ARGS...]",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Start a background process in the sandbox",
+		Aliases:           []string{"launch"},
+		Args:              cobra.MinimumNArgs(2),
 		Example: `
 # Start a web server in background
 $> koyeb sandbox start myapp/mysandbox python -m http.server 8080
@@ -123,38 +125,42 @@ $> koyeb sandbox start myapp/mysandbox --cwd /app npm start
 
 	// ps command - list processes
 	psCmd := &cobra.Command{
-		Use:     "ps NAME",
-		Short:   "List background processes in the sandbox",
-		Aliases: []string{"list-processes"},
-		Args:    cobra.ExactArgs(1),
-		RunE:    WithCLIContext(h.ListProcesses),
+		Use:               "ps NAME",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "List background processes in the sandbox",
+		Aliases:           []string{"list-processes"},
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.ListProcesses),
 	}
 	sandboxCmd.AddCommand(psCmd)
 
 	// kill command - kill a process
 	killCmd := &cobra.Command{
-		Use:   "kill NAME PROCESS_ID",
-		Short: "Kill a background process in the sandbox",
-		Args:  cobra.ExactArgs(2),
-		RunE:  WithCLIContext(h.KillProcess),
+		Use:               "kill NAME PROCESS_ID",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Kill a background process in the sandbox",
+		Args:              cobra.ExactArgs(2),
+		RunE:              WithCLIContext(h.KillProcess),
 	}
 	sandboxCmd.AddCommand(killCmd)
 
 	// logs command - stream process logs
 	processLogsCmd := &cobra.Command{
-		Use:   "logs NAME PROCESS_ID",
-		Short: "Stream logs from a background process",
-		Args:  cobra.ExactArgs(2),
-		RunE:  WithCLIContext(h.ProcessLogs),
+		Use:               "logs NAME PROCESS_ID",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Stream logs from a background process",
+		Args:              cobra.ExactArgs(2),
+		RunE:              WithCLIContext(h.ProcessLogs),
 	}
 	processLogsCmd.Flags().BoolP("follow", "f", false, "Follow log output (like tail -f)")
 	sandboxCmd.AddCommand(processLogsCmd)
 
 	// expose-port command
 	exposePortCmd := &cobra.Command{
-		Use:   "expose-port NAME PORT",
-		Short: "Expose a port from the sandbox via TCP proxy",
-		Args:  cobra.ExactArgs(2),
+		Use:               "expose-port NAME PORT",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Expose a port from the sandbox via TCP proxy",
+		Args:              cobra.ExactArgs(2),
 		Example: `
 # Expose port 8080
 $> koyeb sandbox expose-port myapp/mysandbox 8080
@@ -165,19 +171,21 @@ $> koyeb sandbox expose-port myapp/mysandbox 8080
 
 	// unexpose-port command
 	unexposePortCmd := &cobra.Command{
-		Use:   "unexpose-port NAME",
-		Short: "Unexpose the currently exposed port",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.UnexposePort),
+		Use:               "unexpose-port NAME",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Unexpose the currently exposed port",
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.UnexposePort),
 	}
 	sandboxCmd.AddCommand(unexposePortCmd)
 
 	// health command
 	healthCmd := &cobra.Command{
-		Use:   "health NAME",
-		Short: "Check sandbox health status",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Health),
+		Use:               "health NAME",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Check sandbox health status",
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Health),
 	}
 	sandboxCmd.AddCommand(healthCmd)
 
@@ -190,17 +198,19 @@ $> koyeb sandbox expose-port myapp/mysandbox 8080
 
 	// fs read
 	fsReadCmd := &cobra.Command{
-		Use:   "read NAME PATH",
-		Short: "Read a file from the sandbox",
-		Args:  cobra.ExactArgs(2),
-		RunE:  WithCLIContext(h.FsRead),
+		Use:               "read NAME PATH",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Read a file from the sandbox",
+		Args:              cobra.ExactArgs(2),
+		RunE:              WithCLIContext(h.FsRead),
 	}
 	fsCmd.AddCommand(fsReadCmd)
 
 	// fs write
 	fsWriteCmd := &cobra.Command{
-		Use:   "write NAME PATH [CONTENT]",
-		Short: "Write content to a file in the sandbox",
+		Use:               "write NAME PATH [CONTENT]",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Write content to a file in the sandbox",
 		Long: `Write content to a file in the sandbox.
 Content can be provided as an argument or from a local file with -f flag.
 Parent directories must already exist (create them first with fs mkdir).`,
@@ -219,37 +229,41 @@ $> koyeb sandbox fs write myapp/mysandbox /tmp/script.py -f ./local-script.py
 
 	// fs ls
 	fsLsCmd := &cobra.Command{
-		Use:   "ls NAME [PATH]",
-		Short: "List directory contents in the sandbox",
-		Args:  cobra.RangeArgs(1, 2),
-		RunE:  WithCLIContext(h.FsLs),
+		Use:               "ls NAME [PATH]",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "List directory contents in the sandbox",
+		Args:              cobra.RangeArgs(1, 2),
+		RunE:              WithCLIContext(h.FsLs),
 	}
 	fsLsCmd.Flags().BoolP("long", "l", false, "Use long listing format with details")
 	fsCmd.AddCommand(fsLsCmd)
 
 	// fs mkdir
 	fsMkdirCmd := &cobra.Command{
-		Use:   "mkdir NAME PATH",
-		Short: "Create a directory in the sandbox",
-		Args:  cobra.ExactArgs(2),
-		RunE:  WithCLIContext(h.FsMkdir),
+		Use:               "mkdir NAME PATH",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Create a directory in the sandbox",
+		Args:              cobra.ExactArgs(2),
+		RunE:              WithCLIContext(h.FsMkdir),
 	}
 	fsCmd.AddCommand(fsMkdirCmd)
 
 	// fs rm
 	fsRmCmd := &cobra.Command{
-		Use:   "rm NAME PATH",
-		Short: "Remove a file or directory from the sandbox",
-		Args:  cobra.ExactArgs(2),
-		RunE:  WithCLIContext(h.FsRm),
+		Use:               "rm NAME PATH",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Remove a file or directory from the sandbox",
+		Args:              cobra.ExactArgs(2),
+		RunE:              WithCLIContext(h.FsRm),
 	}
 	fsRmCmd.Flags().BoolP("recursive", "r", false, "Remove directories recursively")
 	fsCmd.AddCommand(fsRmCmd)
 
 	// fs upload
 	fsUploadCmd := &cobra.Command{
-		Use:   "upload NAME LOCAL_PATH REMOTE_PATH",
-		Short: "Upload a local file or directory to the sandbox (max 1G per file)",
+		Use:               "upload NAME LOCAL_PATH REMOTE_PATH",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Upload a local file or directory to the sandbox (max 1G per file)",
 		Long: `Upload a local file or directory to the sandbox.
 Parent directories on the sandbox must already exist (create them first with fs mkdir).`,
 		Args: cobra.ExactArgs(3),
@@ -261,10 +275,11 @@ Parent directories on the sandbox must already exist (create them first with fs
 
 	// fs download
 	fsDownloadCmd := &cobra.Command{
-		Use:   "download NAME REMOTE_PATH LOCAL_PATH",
-		Short: "Download a file from the sandbox",
-		Args:  cobra.ExactArgs(3),
-		RunE:  WithCLIContext(h.FsDownload),
+		Use:               "download NAME REMOTE_PATH LOCAL_PATH",
+		ValidArgsFunction: completeArgs(completeServices),
+		Short:             "Download a file from the sandbox",
+		Args:              cobra.ExactArgs(3),
+		RunE:              WithCLIContext(h.FsDownload),
 	}
 	fsCmd.AddCommand(fsDownloadCmd)
 
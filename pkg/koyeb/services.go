@@ -26,9 +26,9 @@ func NewServiceCmd() *cobra.Command {
 	}
 
 	createServiceCmd := &cobra.Command{
-		Use:   "create NAME",
+		Use:   "create [NAME]",
 		Short: "Create service",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Example: `
 # Deploy a nginx docker image, listening on port 80
 $> koyeb service create myservice --app myapp --docker nginx --port 80
@@ -42,14 +42,53 @@ $> koyeb service create myservice --app myapp --git github.com/koyeb/example-fla
 # Build and deploy a GitHub repository using docker
 $> koyeb service create myservice --app myapp --git github.com/org/name --git-branch main --git-builder docker
 
+# Build and deploy the current commit of the local git repository, pushed to GitHub
+$> koyeb service create myservice --app myapp --git .
+
 # Create a docker service, only accessible from the mesh (--route is not automatically created for TCP ports)
 $> koyeb service create myservice --app myapp --docker nginx --port 80:tcp
+
+# Create a service from a definition file, as exported by koyeb service get -o definition, and override the instance type
+$> koyeb service create --app myapp -f svc.yaml --instance-type small
+
+# Answer the questions of the wizard to configure the service, and print the equivalent command
+$> koyeb service create --interactive
 `,
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			createService := koyeb.NewCreateServiceWithDefaults()
 			createDefinition := koyeb.NewDeploymentDefinitionWithDefaults()
 
-			err := h.parseServiceDefinitionFlags(ctx, cmd.Flags(), createDefinition)
+			fileDefinition, err := h.parseServiceDefinitionFile(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			if fileDefinition != nil {
+				createDefinition = fileDefinition
+			}
+
+			if GetBoolFlags(cmd, "interactive") {
+				var ok bool
+				args, ok, err = h.runCreateWizard(ctx, cmd, args, createDefinition)
+				if err != nil || !ok {
+					return err
+				}
+			}
+
+			// The service name can be omitted when it is provided in the definition file
+			if len(args) == 0 {
+				if createDefinition.GetName() == "" {
+					return &errors.CLIError{
+						What:       "Error while creating the service",
+						Why:        "the service name has not been provided",
+						Additional: nil,
+						Orig:       nil,
+						Solution:   "Specify the service name as argument, or set the key \"name\" in the file provided with --file",
+					}
+				}
+				args = []string{createDefinition.GetName()}
+			}
+
+			err = h.parseServiceDefinitionFlags(ctx, cmd.Flags(), createDefinition)
 			if err != nil {
 				return err
 			}
@@ -71,35 +110,46 @@ $> koyeb service create myservice --app myapp --docker nginx --port 80:tcp
 		}),
 	}
 	h.addServiceDefinitionFlags(createServiceCmd.Flags())
+	h.addServiceDefinitionFileFlag(createServiceCmd.Flags())
+	createServiceCmd.RegisterFlagCompletionFunc("type", completeServiceTypes) //nolint:errcheck
 	createServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	createServiceCmd.Flags().Bool("wait", false, "Waits until service deployment is done")
 	createServiceCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
+	createServiceCmd.Flags().Bool("logs", false, "Tail the build logs, then the runtime logs of the deployment while waiting. Implies --wait")
+	createServiceCmd.Flags().BoolP("interactive", "i", false, "Ask the settings of the service interactively, and print the equivalent command")
 	serviceCmd.AddCommand(createServiceCmd)
 
 	getServiceCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get service",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Get),
+		Use:               "get NAME",
+		Short:             "Get service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		Example: `
+# Export the definition of the service "myservice", in the format accepted by koyeb service create -f and koyeb service update -f
+$> koyeb service get myapp/myservice -o definition > svc.yaml
+`,
+		RunE: WithCLIContext(h.Get),
 	}
 	getServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	serviceCmd.AddCommand(getServiceCmd)
 
 	unappliedChangesCmd := &cobra.Command{
-		Use:   "unapplied-changes SERVICE_NAME",
-		Short: "Show unapplied changes saved with the --save-only flag, which will be applied in the next deployment",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.ShowUnappliedChanges),
+		Use:               "unapplied-changes SERVICE_NAME",
+		Short:             "Show unapplied changes saved with the --save-only flag, which will be applied in the next deployment",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.ShowUnappliedChanges),
 	}
 	unappliedChangesCmd.Flags().StringP("app", "a", "", "Service application")
 	serviceCmd.AddCommand(unappliedChangesCmd)
 
 	var since dates.HumanFriendlyDate
 	logsServiceCmd := &cobra.Command{
-		Use:     "logs NAME",
-		Aliases: []string{"l", "log"},
-		Short:   "Get the service logs",
-		Args:    cobra.ExactArgs(1),
+		Use:               "logs NAME",
+		Aliases:           []string{"l", "log"},
+		Short:             "Get the service logs",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			return h.Logs(ctx, cmd, since.Time, args)
 		}),
@@ -107,6 +157,7 @@ $> koyeb service create myservice --app myapp --docker nginx --port 80:tcp
 	logsServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	logsServiceCmd.Flags().String("instance", "", "Instance")
 	logsServiceCmd.Flags().StringP("type", "t", "", "Type (runtime, build)")
+	logsServiceCmd.RegisterFlagCompletionFunc("type", completeLogTypes) //nolint:errcheck
 	logsServiceCmd.Flags().Var(&since, "since", "DEPRECATED. Use --tail --start-time instead.")
 	logsServiceCmd.Flags().Bool("tail", false, "Tail logs if no --end-time is provided.")
 	logsServiceCmd.Flags().String("start-time", "", "Return logs after this date")
@@ -126,28 +177,74 @@ $> koyeb service create myservice --app myapp --docker nginx --port 80:tcp
 	listServiceCmd.Flags().StringP("name", "n", "", "Service name")
 
 	describeServiceCmd := &cobra.Command{
-		Use:   "describe NAME",
-		Short: "Describe service",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Describe),
+		Use:               "describe NAME",
+		Short:             "Describe service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Describe),
 	}
 	describeServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	serviceCmd.AddCommand(describeServiceCmd)
 
 	execServiceCmd := &cobra.Command{
-		Use:     "exec NAME CMD -- [args...]",
-		Short:   "Run a command in the context of an instance selected among the service instances",
-		Aliases: []string{"run", "attach"},
-		Args:    cobra.MinimumNArgs(2),
-		RunE:    WithCLIContext(h.Exec),
+		Use:               "exec NAME CMD -- [args...]",
+		Short:             "Run a command in the context of an instance selected among the service instances",
+		ValidArgsFunction: completeArgs(completeServices),
+		Long:              "Run a command in the context of an instance selected among the service instances." + execModesHelp,
+		Aliases:           []string{"run", "attach"},
+		Args:              cobra.MinimumNArgs(2),
+		Example: `
+# Pipe data to a command of the service, without a TTY
+$> cat dump.sql | koyeb service exec myapp/myservice -- psql "$DATABASE_URL"
+
+# Run a command on all the running instances of the service. The output lines are prefixed with the instance ID and region,
+# and the command exits with the highest exit code of the instances
+$> koyeb service exec myapp/myservice --all-instances -- df -h
+`,
+		RunE: WithCLIContext(h.Exec),
 	}
 	execServiceCmd.Flags().StringP("app", "a", "", "Service application")
+	execServiceCmd.Flags().Bool("all-instances", false, "Run the command on all the running instances of the service, instead of a single instance. The standard input is not forwarded")
+	execServiceCmd.Flags().BoolP("no-tty", "T", false, "Run the command without a TTY, even if the standard input and output are terminals")
+	execServiceCmd.Flags().String("record", "", "Record the session to this file in the asciicast v2 format, which can be played with koyeb exec replay")
+	execServiceCmd.MarkFlagsMutuallyExclusive("all-instances", "record")
 	serviceCmd.AddCommand(execServiceCmd)
 
+	eventsServiceCmd := &cobra.Command{
+		Use:               "events NAME",
+		Short:             "List the events of a service, of its recent deployments and of their instances",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		Example: `
+# Display the events of the service, and the new events until Ctrl+C is pressed
+$> koyeb service events myapp/myservice --watch
+`,
+		RunE: WithCLIContext(h.Events),
+	}
+	eventsServiceCmd.Flags().StringP("app", "a", "", "Service application")
+	addEventsFlags(eventsServiceCmd)
+	serviceCmd.AddCommand(eventsServiceCmd)
+
+	portForwardServiceCmd := &cobra.Command{
+		Use:               "port-forward NAME [LOCAL_PORT:]REMOTE_PORT...",
+		Short:             "Forward local ports to a healthy instance of the service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.MinimumNArgs(2),
+		Example: `
+# Listen on the local port 8080 and forward the connections to the port 9000 of an instance of the service
+$> koyeb service port-forward myapp/myservice 8080:9000
+`,
+		RunE: WithCLIContext(h.PortForward),
+	}
+	portForwardServiceCmd.Flags().StringP("app", "a", "", "Service application")
+	addPortForwardFlags(portForwardServiceCmd)
+	serviceCmd.AddCommand(portForwardServiceCmd)
+
 	updateServiceCmd := &cobra.Command{
-		Use:   "update NAME",
-		Short: "Update service",
-		Args:  cobra.ExactArgs(1),
+		Use:               "update NAME",
+		Short:             "Update service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
 		Example: `
 # Update the service "myservice" in the app "myapp", upsert the environment variable PORT and delete the environment variable DEBUG
 $> koyeb service update myapp/myservice --env PORT=8001 --env '!DEBUG'
@@ -158,6 +255,9 @@ $> koyeb service update myapp/myservice --docker-command nginx --docker-args '-g
 # Given a public service configured with the port 80:http and the route /:80, update it to make the service private, ie. only
 # accessible from the mesh, by changing the port's protocol and removing the route
 $> koyeb service update myapp/myservice --port 80:tcp --route '!/'
+
+# Replace the configuration of the service "myservice" with the definition stored in svc.yaml
+$> koyeb service update myapp/myservice -f svc.yaml
 `,
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			serviceName, err := h.parseServiceName(cmd, args[0])
@@ -199,18 +299,29 @@ $> koyeb service update myapp/myservice --port 80:tcp --route '!/'
 
 			var updateDef *koyeb.DeploymentDefinition
 
-			// If the --override flag is set, we start from a new deployment
-			// definition with default values. Otherwise, we start from the
-			// latest deployment definition.
+			fileDefinition, err := h.parseServiceDefinitionFile(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			// If the --file flag is set, we start from the definition of the
+			// file. If the --override flag is set, we start from a new
+			// deployment definition with default values. Otherwise, we start
+			// from the latest deployment definition.
 			override, _ := cmd.Flags().GetBool("override")
-			if override {
+			if fileDefinition != nil {
+				updateDef = fileDefinition
+				if !updateDef.HasName() {
+					updateDef.Name = latestDeploy.GetDeployments()[0].Definition.Name
+				}
+			} else if override {
 				updateDef = koyeb.NewDeploymentDefinitionWithDefaults()
 				updateDef.Name = latestDeploy.GetDeployments()[0].Definition.Name
 			} else {
 				updateDef = latestDeploy.GetDeployments()[0].Definition
 			}
 
-			if updateDef.Git != nil && updateDef.Git.GetSha() != "" && !cmd.Flags().Lookup("git-sha").Changed {
+			if fileDefinition == nil && updateDef.Git != nil && updateDef.Git.GetSha() != "" && !cmd.Flags().Lookup("git-sha").Changed && !isLocalGitPath(GetStringFlags(cmd, "git")) {
 				logrus.Warnf(
 					"Warning: you are updating the service without specifying a commit with the --git-sha flag, and the service is currently deployed with the specific commit %s. If you want to deploy the latest commit of the branch instead, use --git-sha ''.",
 					updateDef.Git.GetSha(),
@@ -252,6 +363,8 @@ $> koyeb service update myapp/myservice --port 80:tcp --route '!/'
 		}),
 	}
 	h.addServiceDefinitionFlags(updateServiceCmd.Flags())
+	h.addServiceDefinitionFileFlag(updateServiceCmd.Flags())
+	updateServiceCmd.RegisterFlagCompletionFunc("type", completeServiceTypes) //nolint:errcheck
 	updateServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	updateServiceCmd.Flags().String("name", "", "Specify to update the service name")
 	updateServiceCmd.Flags().Bool("override", false, "Override the service configuration with the new configuration instead of merging them")
@@ -259,52 +372,185 @@ $> koyeb service update myapp/myservice --port 80:tcp --route '!/'
 	updateServiceCmd.Flags().Bool("save-only", false, "Save the new configuration without deploying it")
 	updateServiceCmd.Flags().Bool("wait", false, "Waits until the service deployment is done")
 	updateServiceCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
+	updateServiceCmd.Flags().Bool("logs", false, "Tail the build logs, then the runtime logs of the deployment while waiting. Implies --wait")
 	serviceCmd.AddCommand(updateServiceCmd)
 
 	redeployServiceCmd := &cobra.Command{
-		Use:   "redeploy NAME",
-		Short: "Redeploy service",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.ReDeploy),
+		Use:               "redeploy NAME",
+		Short:             "Redeploy service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.ReDeploy),
 	}
 	redeployServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	redeployServiceCmd.Flags().Bool("skip-build", false, "If there has been at least one past successfully build deployment, use the last one instead of rebuilding. WARNING: this can lead to unexpected behavior if the build depends, for example, on environment variables.")
 	redeployServiceCmd.Flags().Bool("wait", false, "Waits until service deployment is done.")
 	redeployServiceCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
+	redeployServiceCmd.Flags().Bool("logs", false, "Tail the build logs, then the runtime logs of the deployment while waiting. Implies --wait")
 	serviceCmd.AddCommand(redeployServiceCmd)
 	redeployServiceCmd.Flags().Bool("use-cache", false, "Use cache to redeploy")
 
 	deleteServiceCmd := &cobra.Command{
-		Use:   "delete NAME",
-		Short: "Delete service",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Delete),
+		Use:               "delete NAME",
+		Short:             "Delete service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Delete),
 	}
 	deleteServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	serviceCmd.AddCommand(deleteServiceCmd)
 
 	pauseServiceCmd := &cobra.Command{
-		Use:   "pause NAME",
-		Short: "Pause service",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Pause),
+		Use:               "pause NAME",
+		Short:             "Pause service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Pause),
 	}
 	pauseServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	serviceCmd.AddCommand(pauseServiceCmd)
 
 	resumeServiceCmd := &cobra.Command{
-		Use:   "resume NAME",
-		Short: "Resume service",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.Resume),
+		Use:               "resume NAME",
+		Short:             "Resume service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.Resume),
 	}
 	resumeServiceCmd.Flags().StringP("app", "a", "", "Service application")
 	serviceCmd.AddCommand(resumeServiceCmd)
 
+	envCmd := &cobra.Command{
+		Use:   "env ACTION",
+		Short: "Service environment variables",
+	}
+	serviceCmd.AddCommand(envCmd)
+
+	envListCmd := &cobra.Command{
+		Use:               "list NAME",
+		Short:             "List the environment variables of the service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.ListEnv),
+	}
+	envListCmd.Flags().StringP("app", "a", "", "Service application")
+	envCmd.AddCommand(envListCmd)
+
+	envGetCmd := &cobra.Command{
+		Use:               "get NAME KEY",
+		Short:             "Get an environment variable of the service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(2),
+		RunE:              WithCLIContext(h.GetEnv),
+	}
+	envGetCmd.Flags().StringP("app", "a", "", "Service application")
+	envCmd.AddCommand(envGetCmd)
+
+	envSetCmd := &cobra.Command{
+		Use:               "set NAME KEY=VALUE...",
+		Short:             "Set environment variables of the service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.MinimumNArgs(1),
+		Example: `
+# Set the environment variable PORT, and the environment variable DATABASE_URL to the value of the secret "db-url"
+$> koyeb service env set myapp/myservice PORT=8000 DATABASE_URL=@db-url
+
+# Save the new environment variables without deploying them
+$> koyeb service env set myapp/myservice --env-file .env --save-only
+`,
+		RunE: WithCLIContext(h.SetEnv),
+	}
+	envSetCmd.Flags().StringP("app", "a", "", "Service application")
+	envSetCmd.Flags().StringSlice("env-file", []string{}, "Load environment variables from a dotenv file. Variables provided as arguments take precedence.")
+	envSetCmd.Flags().Bool("save-only", false, "Save the new configuration without deploying it")
+	envSetCmd.Flags().Bool("wait", false, "Waits until the service deployment is done")
+	envSetCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
+	envSetCmd.Flags().Bool("logs", false, "Tail the build logs, then the runtime logs of the deployment while waiting. Implies --wait")
+	envCmd.AddCommand(envSetCmd)
+
+	envUnsetCmd := &cobra.Command{
+		Use:               "unset NAME KEY...",
+		Short:             "Remove environment variables from the service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.MinimumNArgs(2),
+		RunE:              WithCLIContext(h.UnsetEnv),
+	}
+	envUnsetCmd.Flags().StringP("app", "a", "", "Service application")
+	envUnsetCmd.Flags().Bool("save-only", false, "Save the new configuration without deploying it")
+	envUnsetCmd.Flags().Bool("wait", false, "Waits until the service deployment is done")
+	envUnsetCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
+	envUnsetCmd.Flags().Bool("logs", false, "Tail the build logs, then the runtime logs of the deployment while waiting. Implies --wait")
+	envCmd.AddCommand(envUnsetCmd)
+
+	envExportCmd := &cobra.Command{
+		Use:               "export NAME",
+		Short:             "Export the environment variables of the service as a dotenv file",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		Example: `
+# Export the environment of the service "myservice" to .env, and load it in another service
+$> koyeb service env export myapp/myservice --file .env
+$> koyeb service update myapp/otherservice --env-file .env
+`,
+		RunE: WithCLIContext(h.ExportEnv),
+	}
+	envExportCmd.Flags().StringP("app", "a", "", "Service application")
+	envExportCmd.Flags().StringP("file", "f", "", "Write the environment variables to this file instead of the standard output")
+	envCmd.AddCommand(envExportCmd)
+
+	cloneServiceCmd := &cobra.Command{
+		Use:               "clone SOURCE DESTINATION",
+		Short:             "Create a copy of a service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Long: `Create a copy of the service SOURCE, named DESTINATION. DESTINATION must be in the format <app>/<service>, and the application must exist.
+
+The clone uses the definition of the latest deployment of SOURCE. Regions, instance types and environment variables can be changed with --map-region, --map-instance-type and --env.
+
+Volumes are not cloned by default. Use --volumes recreate to create new empty volumes with the same size.`,
+		Args: cobra.ExactArgs(2),
+		Example: `
+# Clone the service api of the application staging into the application prod
+$> koyeb service clone staging/api prod/api
+
+# Preview the clone of the service in another region, with another instance type and a different environment variable
+$> koyeb service clone staging/api prod/api --map-region fra:was --map-instance-type small:medium --env ENV=production --dry-run
+`,
+		RunE: WithCLIContext(h.Clone),
+	}
+	addCloneFlags(cloneServiceCmd.Flags())
+	serviceCmd.AddCommand(cloneServiceCmd)
+
+	promoteServiceCmd := &cobra.Command{
+		Use:               "promote SOURCE TARGET",
+		Short:             "Deploy the source of the latest healthy deployment of a service to another service",
+		ValidArgsFunction: completeArgs(completeServices, completeServices),
+		Long: `Deploy the source of the latest healthy deployment of SOURCE to TARGET.
+
+The source is copied exactly: Docker images are pinned to the digest deployed by SOURCE. Services deployed from git or from an archive keep their source type: TARGET is pinned to the commit or the archive deployed by SOURCE, and builds it again. With --use-built-image, TARGET deploys the image built for the deployment of SOURCE as a Docker source, so it runs the same artifact without building it again. This image is stored in the registry of Koyeb: the command checks that it can be pulled before deploying it. The rest of the configuration of TARGET, such as the environment variables, the scaling, the instance type and the regions, is kept.
+
+The changes are displayed before the deployment. Use --dry-run to only display them.`,
+		Args: cobra.ExactArgs(2),
+		Example: `
+# Deploy to production the version of the service running in staging
+$> koyeb service promote staging/api prod/api
+
+# Show the changes without deploying them
+$> koyeb service promote staging/api prod/api --dry-run
+`,
+		RunE: WithCLIContext(h.Promote),
+	}
+	promoteServiceCmd.Flags().Bool("dry-run", false, "Show the changes without deploying them")
+	promoteServiceCmd.Flags().Bool("use-built-image", false, "For git and archive sources, deploy the image built for SOURCE as a Docker source, instead of building the commit or the archive of SOURCE again")
+	promoteServiceCmd.Flags().Bool("wait", false, "Waits until the service deployment is done")
+	promoteServiceCmd.Flags().Duration("wait-timeout", 5*time.Minute, "Duration the wait will last until timeout")
+	promoteServiceCmd.Flags().Bool("logs", false, "Tail the build logs, then the runtime logs of the deployment while waiting. Implies --wait")
+	serviceCmd.AddCommand(promoteServiceCmd)
+
 	scaleCmd := &cobra.Command{
-		Use:   "scale NAME",
-		Short: "Set manual scaling configuration for service (replaces existing configuration)",
-		Args:  cobra.ExactArgs(1),
+		Use:               "scale NAME",
+		Short:             "Set manual scaling configuration for service (replaces existing configuration)",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
 		Example: `
 # Scale a service to 3 instances across all regions
 $> koyeb service scale app/podinfo --instances 3
@@ -327,9 +573,10 @@ $> koyeb service scale app/podinfo --scale fra:5 --scale was:3 --scale sin:2
 	serviceCmd.AddCommand(scaleCmd)
 
 	scaleUpdateCmd := &cobra.Command{
-		Use:   "update NAME",
-		Short: "Update manual scaling configuration for service (patches existing configuration)",
-		Args:  cobra.ExactArgs(1),
+		Use:               "update NAME",
+		Short:             "Update manual scaling configuration for service (patches existing configuration)",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
 		Example: `
 # Update instance count for specific regions, keeping other regions unchanged
 $> koyeb service scale update app/podinfo --scale fra:5
@@ -358,18 +605,20 @@ $> koyeb service scale update app/podinfo --scale fra:5 --scale '!was'
 	scaleCmd.AddCommand(scaleUpdateCmd)
 
 	scaleGetCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get manual scaling configuration for service",
-		Args:  cobra.ExactArgs(1),
-		RunE:  WithCLIContext(h.GetScale),
+		Use:               "get NAME",
+		Short:             "Get manual scaling configuration for service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		RunE:              WithCLIContext(h.GetScale),
 	}
 	scaleGetCmd.Flags().StringP("app", "a", "", "Service application")
 	scaleCmd.AddCommand(scaleGetCmd)
 
 	scaleDeleteCmd := &cobra.Command{
-		Use:   "delete NAME",
-		Short: "Delete manual scaling configuration for service",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete NAME",
+		Short:             "Delete manual scaling configuration for service",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
 		Example: `
 # Remove all manual scaling configuration from a service
 $> koyeb service scale delete app/podinfo
@@ -387,6 +636,9 @@ func NewServiceHandler() *ServiceHandler {
 }
 
 type ServiceHandler struct {
+	// quiet disables the wait and the rendering of the service in Create and
+	// Update, for the callers which wait for and render the services themselves.
+	quiet bool
 }
 
 func (h *ServiceHandler) ResolveServiceArgs(ctx *CLIContext, val string) (string, error) {
@@ -416,6 +668,34 @@ func (h *ServiceHandler) ResolveVolumeArgs(ctx *CLIContext, val string) (string,
 	return id, nil
 }
 
+// getLatestDeployment returns the latest deployment of the service, which holds the current configuration of the service.
+func (h *ServiceHandler) getLatestDeployment(ctx *CLIContext, serviceID string, serviceName string) (*koyeb.DeploymentListItem, error) {
+	latestDeploy, resp, err := ctx.Client.DeploymentsApi.
+		ListDeployments(ctx.Context).
+		Limit("1").
+		ServiceId(serviceID).
+		Execute()
+	if err != nil {
+		return nil, errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while fetching the latest deployment of the service `%s`", serviceName),
+			err,
+			resp,
+		)
+	}
+	if len(latestDeploy.GetDeployments()) == 0 {
+		return nil, &errors.CLIError{
+			What: fmt.Sprintf("Error while fetching the latest deployment of the service `%s`", serviceName),
+			Why:  "we couldn't find the latest deployment of your service",
+			Additional: []string{
+				"When you create a service for the first time, it can take a few seconds for the first deployment to be created.",
+			},
+			Orig:     nil,
+			Solution: "Try again in a few seconds. If the problem persists, please create an issue on https://github.com/koyeb/koyeb-cli/issues/new",
+		}
+	}
+	return &latestDeploy.GetDeployments()[0], nil
+}
+
 func (h *ServiceHandler) addServiceDefinitionFlags(flags *pflag.FlagSet) {
 	h.addServiceDefinitionFlagsForAllSources(flags)
 	h.addServiceDefinitionFlagsForGitSource(flags)
@@ -442,6 +722,13 @@ func (h *ServiceHandler) addServiceDefinitionFlagsForAllSources(flags *pflag.Fla
 			"To use the value of a secret as an environment variable, use the following syntax: --env FOO={{secret.bar}}\n"+
 			"To delete an environment variable, prefix its name with '!', for example --env '!FOO'\n",
 	)
+	flags.StringSlice(
+		"env-file",
+		[]string{},
+		"Load service environment variables from a dotenv file, for example --env-file .env\n"+
+			"Values support quotes, multiline strings, comments and ${VAR} expansion. Use KEY=@SECRET_NAME to reference a secret\n"+
+			"Variables set with --env take precedence. You can specify this flag multiple times\n",
+	)
 	flags.String("instance-type", "nano", "Instance type")
 
 	var strategy DeploymentStrategy
@@ -574,7 +861,7 @@ func (h *ServiceHandler) addServiceDefinitionFlagsForAllSources(flags *pflag.Fla
 
 // Add the flags for Git sources
 func (h *ServiceHandler) addServiceDefinitionFlagsForGitSource(flags *pflag.FlagSet) {
-	flags.String("git", "", "Git repository")
+	flags.String("git", "", `Git repository. If set to a local directory such as ".", the repository, the branch and the commit are read from the local git repository`)
 	flags.String("git-branch", "main", "Git branch")
 	flags.String("git-sha", "", "Git commit SHA to deploy")
 	flags.Bool("git-no-deploy-on-push", false, "Disable new deployments creation when code changes are pushed on the configured branch")
@@ -623,8 +910,12 @@ func (h *ServiceHandler) addServiceDefinitionFlagsForArchiveSource(flags *pflag.
 	flags.StringSlice("archive-ignore-dir", []string{".git", "node_modules", "vendor"},
 		"Set directories to ignore when building the archive.\n"+
 			"To ignore multiple directories, use the flag multiple times.\n"+
-			"To include all directories, set the flag to an empty string.",
+			"To include all directories, set the flag to an empty string.\n"+
+			"Files and directories matching the patterns of .koyebignore, or .dockerignore or .gitignore if it does not exist, are also ignored.\n"+
+			"The patterns of .dockerignore follow the rules of Docker, where all the patterns are relative to the root: use **/*.log to match the files at any level. A negation pattern can include again a file of an ignored directory, for example node_modules then !node_modules/keep.",
 	)
+	flags.Bool("archive-no-cache", false, "Always upload a new archive, even if an archive of the same files has recently been uploaded")
+	flags.Int("archive-compression-level", archiveDefaultCompressionLevel, "Gzip compression level of the archive, from 1 (fastest) to 9 (smallest archive), or 0 to disable compression")
 
 }
 
@@ -658,8 +949,7 @@ func (h *ServiceHandler) parseServiceDefinitionFlags(ctx *CLIContext, flags *pfl
 	}
 	definition.SetStrategy(strategy)
 
-	skipCache, _ := flags.GetBool("skip-cache")
-	definition.SetSkipCache(skipCache)
+	definition.SetSkipCache(h.parseSkipCache(flags, definition.GetSkipCache()))
 
 	envs, err := h.parseEnv(flags, definition.Env)
 	if err != nil {
@@ -871,8 +1161,19 @@ func parseListFlags[T any](
 	return newItems, nil
 }
 
-// Parse --env
+// Parse --env-file and --env. The variables of --env take precedence over the variables loaded from the files.
 func (h *ServiceHandler) parseEnv(flags *pflag.FlagSet, currentEnv []koyeb.DeploymentEnv) ([]koyeb.DeploymentEnv, error) {
+	envFiles, err := flags.GetStringSlice("env-file")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range envFiles {
+		listFlags, err := flags_list.NewEnvListFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+		currentEnv = flags_list.ParseListFlags(listFlags, currentEnv)
+	}
 	return parseListFlags("env", flags_list.NewEnvListFromFlags, flags, currentEnv)
 }
 
@@ -1635,7 +1936,13 @@ func (h *ServiceHandler) parseDockerSource(ctx *CLIContext, flags *pflag.FlagSet
 func (h *ServiceHandler) parseGitSource(flags *pflag.FlagSet, source *koyeb.GitSource) (*koyeb.GitSource, error) {
 	if flags.Lookup("git").Changed {
 		repository, _ := flags.GetString("git")
-		source.SetRepository(repository)
+		if isLocalGitPath(repository) {
+			if err := h.setLocalGitSource(flags, source, repository); err != nil {
+				return nil, err
+			}
+		} else {
+			source.SetRepository(repository)
+		}
 	}
 	if source.GetBranch() == "" || flags.Lookup("git-branch").Changed {
 		branch, _ := flags.GetString("git-branch")
@@ -1945,14 +2252,15 @@ func (h *ServiceHandler) parseArchiveSourceDockerBuilder(flags *pflag.FlagSet, b
 	return &builder, nil
 }
 
-func (h *ServiceHandler) ParseArchiveIgnoreDirectories(flags *pflag.FlagSet, handler *ArchiveHandler) error {
+func (h *ServiceHandler) ParseArchiveFlags(flags *pflag.FlagSet, handler *ArchiveHandler) error {
 	ignoreDirectories, err := flags.GetStringSlice("archive-ignore-dir")
 	if err != nil {
 		return err
 	}
 	handler.ParseIgnoreDirectories(ignoreDirectories)
-	return nil
-
+	handler.noCache, _ = flags.GetBool("archive-no-cache")
+	level, _ := flags.GetInt("archive-compression-level")
+	return handler.ParseCompressionLevel(level)
 }
 
 // DeploymentDefinition contains the keys "env", "scalings" and "instance_types"
@@ -2159,6 +2467,16 @@ func (h *ServiceHandler) checkDockerImage(ctx *CLIContext, source *koyeb.DockerS
 	return nil
 }
 
+// parseSkipCache returns the value of --skip-cache. The value of a definition
+// loaded with --file is kept, unless --skip-cache is set explicitly.
+func (h *ServiceHandler) parseSkipCache(flags *pflag.FlagSet, currentSkipCache bool) bool {
+	if file, _ := flags.GetString("file"); file != "" && !flags.Changed("skip-cache") {
+		return currentSkipCache
+	}
+	skipCache, _ := flags.GetBool("skip-cache")
+	return skipCache
+}
+
 // Parse --volumes
 func (h *ServiceHandler) parseVolumes(ctx *CLIContext, flags *pflag.FlagSet, currentVolumes []koyeb.DeploymentVolume) ([]koyeb.DeploymentVolume, error) {
 	wrappedResolveVolumeId := func(value string) (string, error) {
@@ -0,0 +1,409 @@
+package koyeb
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/flags_list"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// cloneOptions holds the flags shared by `koyeb service clone` and `koyeb app clone`.
+type cloneOptions struct {
+	// Source region -> destination region
+	regions map[string]string
+	// Source instance type -> destination instance type
+	instanceTypes map[string]string
+	env           []flags_list.Flag[koyeb.DeploymentEnv]
+	// Either "skip" or "recreate"
+	volumes string
+	dryRun  bool
+
+	// Not set by flags: overrides used by `koyeb preview create`
+	gitBranch        string
+	serviceLifeCycle *koyeb.ServiceLifeCycle
+	appLifeCycle     *koyeb.AppLifeCycle
+	// scaleToZero sets the minimum scale of the web services to 0, so they
+	// sleep when they receive no traffic
+	scaleToZero bool
+}
+
+func addCloneFlags(flags *pflag.FlagSet) {
+	flags.StringSlice("map-region", nil, "Deploy the clone in NEW instead of OLD, in the format OLD:NEW. Can be specified multiple times")
+	flags.StringSlice("map-instance-type", nil, "Use the instance type NEW instead of OLD, in the format OLD:NEW. Can be specified multiple times")
+	flags.StringSlice("env", nil, "Override an environment variable of the clone, in the format KEY=VALUE or KEY={{secret.SECRET_NAME}}. To remove a variable, use !KEY. Can be specified multiple times")
+	flags.String("volumes", "skip", `What to do with the volumes attached to the source: "skip" to clone the service without volumes, "recreate" to create new empty volumes with the same size`)
+	flags.Bool("dry-run", false, "Show what would be created, without creating anything")
+}
+
+func parseCloneOptions(flags *pflag.FlagSet) (*cloneOptions, error) {
+	opts := &cloneOptions{}
+
+	mapRegions, _ := flags.GetStringSlice("map-region")
+	regions, err := parseMappingFlag("map-region", mapRegions)
+	if err != nil {
+		return nil, err
+	}
+	opts.regions = regions
+
+	mapInstanceTypes, _ := flags.GetStringSlice("map-instance-type")
+	instanceTypes, err := parseMappingFlag("map-instance-type", mapInstanceTypes)
+	if err != nil {
+		return nil, err
+	}
+	opts.instanceTypes = instanceTypes
+
+	envValues, _ := flags.GetStringSlice("env")
+	env, err := flags_list.NewEnvListFromFlags(envValues)
+	if err != nil {
+		return nil, err
+	}
+	opts.env = env
+
+	opts.volumes, _ = flags.GetString("volumes")
+	if opts.volumes != "skip" && opts.volumes != "recreate" {
+		return nil, &errors.CLIError{
+			What:       "Error while parsing the --volumes flag",
+			Why:        fmt.Sprintf("invalid value %q", opts.volumes),
+			Additional: []string{`The value must be either "skip" or "recreate"`},
+			Orig:       nil,
+			Solution:   "Fix the flag and try again",
+		}
+	}
+
+	opts.dryRun, _ = flags.GetBool("dry-run")
+	return opts, nil
+}
+
+// parseMappingFlag parses values in the format OLD:NEW.
+func parseMappingFlag(flag string, values []string) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, value := range values {
+		from, to, found := strings.Cut(value, ":")
+		if !found || from == "" || to == "" {
+			return nil, &errors.CLIError{
+				What:       fmt.Sprintf("Error while parsing the --%s flag", flag),
+				Why:        fmt.Sprintf("the value %q is invalid", value),
+				Additional: []string{"The value must be in the format OLD:NEW"},
+				Orig:       nil,
+				Solution:   "Fix the flag and try again",
+			}
+		}
+		ret[from] = to
+	}
+	return ret, nil
+}
+
+// cloneDefinition returns a copy of the definition named name, with the
+// regions, instance types and environment variables updated according to the
+// options. The volumes are removed if they are skipped, and otherwise keep the
+// source volume IDs: the caller is responsible for replacing them.
+func (h *ServiceHandler) cloneDefinition(definition koyeb.DeploymentDefinition, name string, opts *cloneOptions) (koyeb.DeploymentDefinition, error) {
+	// Copy the definition, to avoid modifying the slices of the source
+	var clone koyeb.DeploymentDefinition
+	raw, err := json.Marshal(definition)
+	if err == nil {
+		err = json.Unmarshal(raw, &clone)
+	}
+	if err != nil {
+		return clone, &errors.CLIError{
+			What:       "Error while cloning the service",
+			Why:        "unable to copy the definition of the service",
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Please, create an issue on https://github.com/koyeb/koyeb-cli/issues/new and provide your service ID",
+		}
+	}
+	clone.SetName(name)
+
+	regions := []string{}
+	for _, region := range clone.GetRegions() {
+		if to, ok := opts.regions[region]; ok {
+			region = to
+		}
+		if !slices.Contains(regions, region) {
+			regions = append(regions, region)
+		}
+	}
+	clone.SetRegions(regions)
+
+	remapScope := func(scopes []string) []string {
+		ret := []string{}
+		for _, scope := range scopes {
+			if region, ok := strings.CutPrefix(scope, "region:"); ok {
+				if to, ok := opts.regions[region]; ok {
+					scope = "region:" + to
+				}
+			}
+			if !slices.Contains(ret, scope) {
+				ret = append(ret, scope)
+			}
+		}
+		return ret
+	}
+	for idx := range clone.Env {
+		clone.Env[idx].Scopes = remapScope(clone.Env[idx].Scopes)
+	}
+	for idx := range clone.Scalings {
+		clone.Scalings[idx].Scopes = remapScope(clone.Scalings[idx].Scopes)
+	}
+	for idx := range clone.InstanceTypes {
+		clone.InstanceTypes[idx].Scopes = remapScope(clone.InstanceTypes[idx].Scopes)
+		if to, ok := opts.instanceTypes[clone.InstanceTypes[idx].GetType()]; ok {
+			clone.InstanceTypes[idx].SetType(to)
+		}
+	}
+	for idx := range clone.Volumes {
+		clone.Volumes[idx].Scopes = remapScope(clone.Volumes[idx].Scopes)
+	}
+
+	if opts.volumes == "skip" {
+		clone.Volumes = nil
+	}
+
+	if opts.gitBranch != "" && clone.Git != nil {
+		clone.Git.SetBranch(opts.gitBranch)
+		clone.Git.Sha = nil
+		clone.Git.Tag = nil
+	}
+
+	if opts.scaleToZero {
+		if clone.GetType() == koyeb.DEPLOYMENTDEFINITIONTYPE_WEB {
+			for idx := range clone.Scalings {
+				clone.Scalings[idx].SetMin(0)
+			}
+		} else {
+			log.Warnf("The service `%s` is not a web service and can not sleep, so it will not be deleted for inactivity", name)
+		}
+	}
+
+	if len(opts.env) > 0 {
+		clone.SetEnv(flags_list.ParseListFlags(opts.env, clone.GetEnv()))
+		// Set the scopes of the new variables to the regions of the clone
+		h.setRegions(&clone, clone.GetRegions())
+	}
+	return clone, nil
+}
+
+// recreateVolumes creates a new empty volume for each volume of the
+// definition, and updates the definition to use them. The new volumes are
+// named <prefix><source volume name>, and have the size of the source volumes.
+// It returns the created volumes. If a volume can not be created, the volumes
+// already created are deleted.
+func (h *ServiceHandler) recreateVolumes(ctx *CLIContext, definition *koyeb.DeploymentDefinition, prefix string, opts *cloneOptions, reply *CloneReply) ([]koyeb.PersistentVolume, error) {
+	created := []koyeb.PersistentVolume{}
+	for idx, volume := range definition.Volumes {
+		res, resp, err := ctx.Client.PersistentVolumesApi.GetPersistentVolume(ctx.Context, volume.GetId()).Execute()
+		if err != nil {
+			h.deleteClonedVolumes(ctx, created)
+			return nil, errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while retrieving the volume `%s`", volume.GetId()),
+				err,
+				resp,
+			)
+		}
+		source := res.GetVolume()
+
+		region := source.GetRegion()
+		if to, ok := opts.regions[region]; ok {
+			region = to
+		}
+
+		createVolume := koyeb.NewCreatePersistentVolumeRequestWithDefaults()
+		createVolume.SetName(prefix + source.GetName())
+		createVolume.SetRegion(region)
+		createVolume.SetMaxSize(source.GetMaxSize())
+		createVolume.SetVolumeType(source.GetBackingStore())
+
+		details := fmt.Sprintf("%dGB in %s, mounted on %s", source.GetMaxSize(), region, volume.GetPath())
+		reply.add("volume", source.GetName(), createVolume.GetName(), details)
+		if opts.dryRun {
+			continue
+		}
+
+		createRes, resp, err := ctx.Client.PersistentVolumesApi.CreatePersistentVolume(ctx.Context).Body(*createVolume).Execute()
+		if err != nil {
+			h.deleteClonedVolumes(ctx, created)
+			return nil, errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while creating the volume `%s`", createVolume.GetName()),
+				err,
+				resp,
+			)
+		}
+		created = append(created, createRes.GetVolume())
+		definition.Volumes[idx].SetId(createRes.Volume.GetId())
+	}
+	return created, nil
+}
+
+// deleteClonedVolumes deletes the volumes created by recreateVolumes when the
+// clone fails, so they do not leak. The volumes which can not be deleted are
+// reported, to be deleted by hand.
+func (h *ServiceHandler) deleteClonedVolumes(ctx *CLIContext, volumes []koyeb.PersistentVolume) {
+	for _, volume := range volumes {
+		_, _, err := ctx.Client.PersistentVolumesApi.DeletePersistentVolume(ctx.Context, volume.GetId()).Execute()
+		if err != nil {
+			log.Warnf("Unable to delete the volume `%s` created for the clone. Delete it with `koyeb volume delete %s`.", volume.GetName(), volume.GetId())
+			continue
+		}
+		log.Infof("The volume `%s` created for the clone has been deleted", volume.GetName())
+	}
+}
+
+// cloneService clones the latest deployment of the service sourceID into the
+// application dstAppID. The volumes are recreated if requested. When
+// opts.dryRun is set, only reply is filled.
+func (h *ServiceHandler) cloneService(ctx *CLIContext, sourceID, sourceName, dstAppID, dstAppName, dstName string, opts *cloneOptions, reply *CloneReply) error {
+	resService, resp, err := ctx.Client.ServicesApi.GetService(ctx.Context, sourceID).Execute()
+	if err != nil {
+		return errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while retrieving the service `%s`", sourceName),
+			err,
+			resp,
+		)
+	}
+
+	latestDeploy, err := h.getLatestDeployment(ctx, sourceID, sourceName)
+	if err != nil {
+		return err
+	}
+
+	definition, err := h.cloneDefinition(latestDeploy.GetDefinition(), dstName, opts)
+	if err != nil {
+		return err
+	}
+
+	reply.add("service", sourceName, fmt.Sprintf("%s/%s", dstAppName, dstName), fmt.Sprintf("%s in %s",
+		renderInstanceTypes(definition.GetInstanceTypes()),
+		renderRegions(definition.GetRegions()),
+	))
+	for _, volume := range latestDeploy.GetDefinition().Volumes {
+		if opts.volumes == "skip" {
+			log.Warnf("The volume %s mounted on %s in `%s` is not cloned. Use --volumes recreate to create new empty volumes.", volume.GetId()[:8], volume.GetPath(), sourceName)
+		}
+	}
+	var volumes []koyeb.PersistentVolume
+	if opts.volumes == "recreate" {
+		volumes, err = h.recreateVolumes(ctx, &definition, fmt.Sprintf("%s-%s-", dstAppName, dstName), opts, reply)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.dryRun {
+		return nil
+	}
+
+	createService := koyeb.NewCreateServiceWithDefaults()
+	createService.SetAppId(dstAppID)
+	createService.SetDefinition(definition)
+	if opts.serviceLifeCycle != nil {
+		createService.SetLifeCycle(*opts.serviceLifeCycle)
+	} else if resService.Service.HasLifeCycle() {
+		createService.SetLifeCycle(resService.Service.GetLifeCycle())
+	}
+
+	_, resp, err = ctx.Client.ServicesApi.CreateService(ctx.Context).Service(*createService).Execute()
+	if err != nil {
+		h.deleteClonedVolumes(ctx, volumes)
+		return errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while creating the service `%s/%s`", dstAppName, dstName),
+			err,
+			resp,
+		)
+	}
+	return nil
+}
+
+func (h *ServiceHandler) Clone(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	opts, err := parseCloneOptions(cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	sourceName, err := h.parseServiceName(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	source, err := h.ResolveServiceArgs(ctx, sourceName)
+	if err != nil {
+		return err
+	}
+
+	dstAppName, err := h.parseAppName(cmd, args[1])
+	if err != nil {
+		return err
+	}
+	dstName, err := h.parseServiceNameWithoutApp(cmd, args[1])
+	if err != nil {
+		return err
+	}
+	dstApp, err := h.ResolveAppArgs(ctx, dstAppName)
+	if err != nil {
+		return err
+	}
+
+	reply := NewCloneReply(opts.dryRun)
+	if err := h.cloneService(ctx, source, sourceName, dstApp, dstAppName, dstName, opts, reply); err != nil {
+		return err
+	}
+	ctx.Renderer.Render(reply)
+	return nil
+}
+
+func renderInstanceTypes(instanceTypes []koyeb.DeploymentInstanceType) string {
+	types := []string{}
+	for _, instanceType := range instanceTypes {
+		if !slices.Contains(types, instanceType.GetType()) {
+			types = append(types, instanceType.GetType())
+		}
+	}
+	if len(types) == 0 {
+		return "-"
+	}
+	return strings.Join(types, ",")
+}
+
+// CloneReply lists the resources created by a clone command, or which would be created with --dry-run.
+type CloneReply struct {
+	dryRun bool
+	items  []map[string]string
+}
+
+func NewCloneReply(dryRun bool) *CloneReply {
+	return &CloneReply{dryRun: dryRun, items: []map[string]string{}}
+}
+
+func (r *CloneReply) add(resource, source, clone, details string) {
+	r.items = append(r.items, map[string]string{
+		"resource": resource,
+		"source":   source,
+		"clone":    clone,
+		"details":  details,
+	})
+}
+
+func (r *CloneReply) Title() string {
+	if r.dryRun {
+		return "Resources to clone (dry run)"
+	}
+	return "Cloned resources"
+}
+
+func (r *CloneReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.items)
+}
+
+func (r *CloneReply) Headers() []string {
+	return []string{"resource", "source", "clone", "details"}
+}
+
+func (r *CloneReply) Fields() []map[string]string {
+	return r.items
+}
@@ -22,7 +22,8 @@ func (h *ServiceHandler) Create(ctx *CLIContext, cmd *cobra.Command, args []stri
 		return err
 	}
 
-	wait, _ := cmd.Flags().GetBool("wait")
+	// --logs implies --wait
+	wait := !h.quiet && (GetBoolFlags(cmd, "wait") || GetBoolFlags(cmd, "logs"))
 	waitTimeout, _ := cmd.Flags().GetDuration("wait-timeout")
 
 	resApp, resp, err := ctx.Client.AppsApi.GetApp(ctx.Context, app).Execute()
@@ -49,6 +50,9 @@ func (h *ServiceHandler) Create(ctx *CLIContext, cmd *cobra.Command, args []stri
 		res.Service.GetId()[:8],
 	)
 	defer func() {
+		if h.quiet {
+			return
+		}
 		res, _, err := ctx.Client.ServicesApi.GetService(ctx.Context, res.Service.GetId()).Execute()
 		if err != nil {
 			return
@@ -62,6 +66,11 @@ func (h *ServiceHandler) Create(ctx *CLIContext, cmd *cobra.Command, args []stri
 		ctxd, cancel := context.WithTimeout(ctx.Context, waitTimeout)
 		defer cancel()
 
+		if GetBoolFlags(cmd, "logs") {
+			follower := FollowDeploymentLogs(ctx, res.Service.GetLatestDeploymentId(), GetBoolFlags(cmd, "full"))
+			defer follower.Stop()
+		}
+
 		for range ticker(ctxd, 2*time.Second) {
 			res, resp, err := ctx.Client.ServicesApi.GetService(ctxd, res.Service.GetId()).Execute()
 			if err != nil {
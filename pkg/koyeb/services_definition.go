@@ -0,0 +1,87 @@
+package koyeb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/spf13/pflag"
+)
+
+// addServiceDefinitionFileFlag adds the --file flag to `koyeb service create` and `koyeb service update`.
+func (h *ServiceHandler) addServiceDefinitionFileFlag(flags *pflag.FlagSet) {
+	flags.StringP(
+		"file",
+		"f",
+		"",
+		"Load the service definition from a YAML or JSON file, or from stdin if set to '-'\n"+
+			"The file uses the format of `koyeb service get -o definition`. Other flags are applied on top of the definition loaded from the file.\n",
+	)
+}
+
+// parseServiceDefinitionFile returns the definition loaded from the file given with --file, or nil if the flag is not set.
+func (h *ServiceHandler) parseServiceDefinitionFile(flags *pflag.FlagSet) (*koyeb.DeploymentDefinition, error) {
+	path, _ := flags.GetString("file")
+	if path == "" {
+		return nil, nil
+	}
+	return loadDeploymentDefinitionFile(path)
+}
+
+// loadDeploymentDefinitionFile reads a deployment definition from a YAML or
+// JSON file. Unknown keys are rejected to catch typos, which would otherwise be
+// silently ignored by the API.
+func loadDeploymentDefinitionFile(path string) (*koyeb.DeploymentDefinition, error) {
+	var data []byte
+	var err error
+
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, &errors.CLIError{
+			What:       "Error while loading the service definition",
+			Why:        fmt.Sprintf("unable to read the file %s", path),
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Make sure the file exists and is readable, then try again",
+		}
+	}
+
+	// JSON is a subset of YAML, so both formats are accepted.
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, &errors.CLIError{
+			What:       "Error while loading the service definition",
+			Why:        fmt.Sprintf("the file %s is not a valid YAML or JSON document", path),
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Fix the syntax of the file and try again",
+		}
+	}
+
+	// Start from the default definition so the type is set to INVALID when
+	// omitted, which lets parseServiceDefinitionFlags apply the default type.
+	definition := koyeb.NewDeploymentDefinitionWithDefaults()
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(definition); err != nil {
+		return nil, &errors.CLIError{
+			What: "Error while loading the service definition",
+			Why:  fmt.Sprintf("the file %s does not contain a valid service definition", path),
+			Additional: []string{
+				"The file must use the format of `koyeb service get -o definition`.",
+			},
+			Orig:     err,
+			Solution: "Fix the definition and try again",
+		}
+	}
+	return definition, nil
+}
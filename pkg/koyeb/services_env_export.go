@@ -0,0 +1,89 @@
+package koyeb
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/dotenv"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func (h *ServiceHandler) ExportEnv(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	serviceName, err := h.parseServiceName(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	service, err := h.ResolveServiceArgs(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	latestDeploy, err := h.getLatestDeployment(ctx, service, serviceName)
+	if err != nil {
+		return err
+	}
+
+	vars := envToDotenvVariables(latestDeploy.Definition.GetEnv())
+
+	var out io.Writer = os.Stdout
+	if path := GetStringFlags(cmd, "file"); path != "" {
+		// The file might contain sensitive values, so it is only readable by the current user
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return &errors.CLIError{
+				What:       "Error while exporting the environment variables",
+				Why:        fmt.Sprintf("unable to open the file %s", path),
+				Additional: nil,
+				Orig:       err,
+				Solution:   "Make sure the directory exists and is writable, then try again",
+			}
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := dotenv.Write(out, vars); err != nil {
+		return &errors.CLIError{
+			What:       "Error while exporting the environment variables",
+			Why:        "unable to write the environment file",
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Try again. If the problem persists, please create an issue on https://github.com/koyeb/koyeb-cli/issues/new",
+		}
+	}
+	return nil
+}
+
+// envToDotenvVariables converts the environment of a deployment to dotenv
+// variables. The API allows a variable to have different values per region, but
+// a dotenv file can only hold one value per key: only the first one is kept.
+func envToDotenvVariables(env []koyeb.DeploymentEnv) []dotenv.Variable {
+	vars := make([]dotenv.Variable, 0, len(env))
+	seen := map[string]dotenv.Variable{}
+	warned := map[string]bool{}
+
+	for _, item := range env {
+		variable := dotenv.Variable{Key: item.GetKey(), Value: item.GetValue()}
+		if item.HasSecret() {
+			variable = dotenv.Variable{Key: item.GetKey(), Value: item.GetSecret(), Secret: true}
+		}
+
+		// A variable set in several regions has one entry per region, which
+		// usually holds the same value
+		if first, ok := seen[item.GetKey()]; ok {
+			if first != variable && !warned[item.GetKey()] {
+				log.Warnf("The environment variable %s has different values depending on the region. Only the first value is exported.", item.GetKey())
+				warned[item.GetKey()] = true
+			}
+			continue
+		}
+		seen[item.GetKey()] = variable
+		vars = append(vars, variable)
+	}
+	return vars
+}
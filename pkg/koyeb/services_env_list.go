@@ -0,0 +1,116 @@
+package koyeb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/spf13/cobra"
+)
+
+func (h *ServiceHandler) ListEnv(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	env, err := h.getServiceEnv(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+	ctx.Renderer.Render(NewListServiceEnvReply(env))
+	return nil
+}
+
+func (h *ServiceHandler) GetEnv(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	env, err := h.getServiceEnv(ctx, cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	// A variable can have several entries if its value depends on the region
+	matching := []koyeb.DeploymentEnv{}
+	for _, item := range env {
+		if item.GetKey() == args[1] {
+			matching = append(matching, item)
+		}
+	}
+	if len(matching) == 0 {
+		return &errors.CLIError{
+			What:       fmt.Sprintf("Error while retrieving the environment variable `%s`", args[1]),
+			Why:        "the environment variable is not configured for the service",
+			Additional: nil,
+			Orig:       nil,
+			Solution:   errors.CLIErrorSolution(fmt.Sprintf("Run `koyeb service env list %s` to list the environment variables of the service", args[0])),
+		}
+	}
+	ctx.Renderer.Render(NewListServiceEnvReply(matching))
+	return nil
+}
+
+// getServiceEnv returns the environment variables of the latest deployment of the service.
+func (h *ServiceHandler) getServiceEnv(ctx *CLIContext, cmd *cobra.Command, name string) ([]koyeb.DeploymentEnv, error) {
+	serviceName, err := h.parseServiceName(cmd, name)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := h.ResolveServiceArgs(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	latestDeploy, err := h.getLatestDeployment(ctx, service, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return latestDeploy.Definition.GetEnv(), nil
+}
+
+type ListServiceEnvReply struct {
+	value []koyeb.DeploymentEnv
+}
+
+func NewListServiceEnvReply(value []koyeb.DeploymentEnv) *ListServiceEnvReply {
+	return &ListServiceEnvReply{
+		value: value,
+	}
+}
+
+func (ListServiceEnvReply) Title() string {
+	return "Environment variables"
+}
+
+func (r *ListServiceEnvReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.value)
+}
+
+func (r *ListServiceEnvReply) Headers() []string {
+	return []string{"key", "type", "value", "regions"}
+}
+
+func (r *ListServiceEnvReply) Fields() []map[string]string {
+	resp := make([]map[string]string, 0, len(r.value))
+
+	for _, item := range r.value {
+		fields := map[string]string{
+			"key":     item.GetKey(),
+			"type":    "value",
+			"value":   item.GetValue(),
+			"regions": formatEnvScopes(item.GetScopes()),
+		}
+		if item.HasSecret() {
+			fields["type"] = "secret"
+			fields["value"] = item.GetSecret()
+		}
+		resp = append(resp, fields)
+	}
+	return resp
+}
+
+// formatEnvScopes displays the regions where the variable is set. Scopes which
+// are not regions are displayed as is.
+func formatEnvScopes(scopes []string) string {
+	regions := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		regions = append(regions, strings.TrimPrefix(scope, "region:"))
+	}
+	return renderRegions(regions)
+}
@@ -0,0 +1,96 @@
+package koyeb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/flags_list"
+	"github.com/spf13/cobra"
+)
+
+func (h *ServiceHandler) SetEnv(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	listFlags := []flags_list.Flag[koyeb.DeploymentEnv]{}
+
+	envFiles, _ := cmd.Flags().GetStringSlice("env-file")
+	for _, path := range envFiles {
+		fileFlags, err := flags_list.NewEnvListFromFile(path)
+		if err != nil {
+			return err
+		}
+		listFlags = append(listFlags, fileFlags...)
+	}
+
+	argsFlags, err := flags_list.NewEnvListFromFlags(args[1:])
+	if err != nil {
+		return err
+	}
+	listFlags = append(listFlags, argsFlags...)
+
+	if len(listFlags) == 0 {
+		return &errors.CLIError{
+			What:       "Error while updating the environment variables",
+			Why:        "no environment variable has been provided",
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Provide at least one KEY=VALUE argument, or an environment file with --env-file",
+		}
+	}
+	return h.updateEnv(ctx, cmd, args[0], listFlags)
+}
+
+func (h *ServiceHandler) UnsetEnv(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	values := make([]string, 0, len(args)-1)
+	for _, key := range args[1:] {
+		values = append(values, "!"+strings.TrimPrefix(key, "!"))
+	}
+
+	listFlags, err := flags_list.NewEnvListFromFlags(values)
+	if err != nil {
+		return err
+	}
+	return h.updateEnv(ctx, cmd, args[0], listFlags)
+}
+
+// updateEnv applies the flags to the environment variables of the latest
+// deployment of the service, and deploys the new configuration.
+func (h *ServiceHandler) updateEnv(ctx *CLIContext, cmd *cobra.Command, name string, listFlags []flags_list.Flag[koyeb.DeploymentEnv]) error {
+	serviceName, err := h.parseServiceName(cmd, name)
+	if err != nil {
+		return err
+	}
+
+	service, err := h.ResolveServiceArgs(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	latestDeploy, err := h.getLatestDeployment(ctx, service, serviceName)
+	if err != nil {
+		return err
+	}
+
+	definition := latestDeploy.Definition
+	definition.SetEnv(flags_list.ReplaceListFlags(listFlags, definition.GetEnv()))
+	// Set the scopes of the new variables to the regions of the service
+	h.setRegions(definition, definition.GetRegions())
+
+	currentService, resp, err := ctx.Client.ServicesApi.GetService(ctx.Context, service).Execute()
+	if err != nil {
+		return errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while fetching service `%s`", serviceName),
+			err,
+			resp,
+		)
+	}
+
+	updateService := koyeb.NewUpdateServiceWithDefaults()
+	updateService.SetDefinition(*definition)
+	if currentService.Service.HasLifeCycle() {
+		updateService.SetLifeCycle(currentService.Service.GetLifeCycle())
+	}
+	updateService.SetSaveOnly(GetBoolFlags(cmd, "save-only"))
+
+	return h.Update(ctx, cmd, []string{name}, updateService)
+}
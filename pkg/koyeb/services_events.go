@@ -0,0 +1,72 @@
+package koyeb
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/spf13/cobra"
+)
+
+// Events displays the events of the service, of its most recent deployments
+// and of their instances.
+func (h *ServiceHandler) Events(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	serviceName, err := h.parseServiceName(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	serviceID, err := h.ResolveServiceArgs(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	return renderEvents(ctx, cmd, func(limit int) ([]resourceEvent, error) {
+		events, err := listServiceEvents(ctx, serviceID, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		deployments, resp, err := ctx.Client.DeploymentsApi.ListDeployments(ctx.Context).
+			ServiceId(serviceID).
+			Limit(strconv.Itoa(eventsRecentDeployments)).
+			Execute()
+		if err != nil {
+			return nil, errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while listing the deployments of the service `%s`", serviceName),
+				err,
+				resp,
+			)
+		}
+		for _, deployment := range deployments.GetDeployments() {
+			deploymentEvents, err := listDeploymentEvents(ctx, deployment.GetId(), limit)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, deploymentEvents...)
+		}
+
+		// All the instances of the service, including the stopped ones which
+		// might have been replaced
+		instances, resp, err := ctx.Client.InstancesApi.ListInstances(ctx.Context).
+			ServiceId(serviceID).
+			Limit(strconv.Itoa(limit)).
+			Execute()
+		if err != nil {
+			return nil, errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while listing the instances of the service `%s`", serviceName),
+				err,
+				resp,
+			)
+		}
+		instanceIDs := []string{}
+		for _, instance := range instances.GetInstances() {
+			instanceIDs = append(instanceIDs, instance.GetId())
+		}
+		instanceEvents, err := listInstanceEvents(ctx, instanceIDs, limit)
+		if err != nil {
+			return nil, err
+		}
+		return append(events, instanceEvents...), nil
+	})
+}
@@ -1,7 +1,9 @@
 package koyeb
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
@@ -20,10 +22,20 @@ func (h *ServiceHandler) Exec(ctx *CLIContext, cmd *cobra.Command, args []string
 		return err
 	}
 
+	if GetBoolFlags(cmd, "all-instances") {
+		return h.ExecAllInstances(ctx, service, serviceName, args[1:])
+	}
+
+	recorder, err := newSessionRecorderFromFlags(cmd, fmt.Sprintf("koyeb service exec %s", args[0]), args[1:])
+	if err != nil {
+		return err
+	}
+
 	returnCode, err := ctx.ExecClient.Exec(ctx.Context, ExecId{
 		Id:   service,
 		Type: koyeb.EXECCOMMANDREQUESTIDTYPE_SERVICE_ID,
-	}, args[1:])
+	}, args[1:], ExecOptions{Recorder: recorder, NoTTY: GetBoolFlags(cmd, "no-tty")})
+	closeSessionRecorder(recorder)
 	if err != nil {
 		return &errors.CLIError{
 			What:       "Error while executing the command",
@@ -33,8 +45,47 @@ func (h *ServiceHandler) Exec(ctx *CLIContext, cmd *cobra.Command, args []string
 			Solution:   "Make sure the command is correct and exists in the service. If the problem persists, try to update the CLI to the latest version.",
 		}
 	}
+	if returnCode < 0 {
+		returnCode = ExecExitCodeConnectionClosed
+	}
 	if returnCode != 0 {
 		os.Exit(returnCode)
 	}
 	return nil
 }
+
+// listInstances returns the instances of the service with one of the given statuses.
+func (h *ServiceHandler) listInstances(ctx *CLIContext, serviceID string, serviceName string, statuses ...koyeb.InstanceStatus) ([]koyeb.InstanceListItem, error) {
+	filter := []string{}
+	for _, status := range statuses {
+		filter = append(filter, string(status))
+	}
+
+	instances := []koyeb.InstanceListItem{}
+	page := int64(0)
+	offset := int64(0)
+	limit := int64(100)
+	for {
+		res, resp, err := ctx.Client.InstancesApi.ListInstances(ctx.Context).
+			Statuses(filter).
+			ServiceId(serviceID).
+			Offset(strconv.FormatInt(offset, 10)).
+			Limit(strconv.FormatInt(limit, 10)).
+			Execute()
+		if err != nil {
+			return nil, errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while listing the instances of the service `%s`", serviceName),
+				err,
+				resp,
+			)
+		}
+		instances = append(instances, res.GetInstances()...)
+
+		page++
+		offset = page * limit
+		if offset >= res.GetCount() {
+			break
+		}
+	}
+	return instances, nil
+}
@@ -0,0 +1,183 @@
+package koyeb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/renderer"
+)
+
+// ExecAllInstances runs the command concurrently on all the running instances
+// of the service. The output lines are prefixed with the instance ID and
+// region, and a summary of the exit codes is displayed at the end. The command
+// exits with the highest exit code of the instances.
+func (h *ServiceHandler) ExecAllInstances(ctx *CLIContext, serviceID string, serviceName string, command []string) error {
+	instances, err := h.listInstances(ctx, serviceID, serviceName, koyeb.INSTANCESTATUS_HEALTHY, koyeb.INSTANCESTATUS_UNHEALTHY)
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		return &errors.CLIError{
+			What:       "Error while executing the command",
+			Why:        fmt.Sprintf("the service `%s` has no running instance", serviceName),
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Wait for the service to be running, or check its status with koyeb service describe",
+		}
+	}
+
+	// Shared by all the instances, so lines of different instances are not mixed
+	var mu sync.Mutex
+	reply := &ExecAllInstancesReply{items: make([]execInstanceResult, len(instances))}
+	var wg sync.WaitGroup
+	for idx, instance := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			prefix := fmt.Sprintf("[%s %s] ", renderer.FormatID(instance.GetId(), false), instance.GetRegion())
+			stdout := &prefixWriter{mu: &mu, w: os.Stdout, prefix: prefix}
+			stderr := &prefixWriter{mu: &mu, w: os.Stderr, prefix: prefix}
+
+			code, err := ctx.ExecClient.ExecWithStreams(ctx.Context, &StdStreams{
+				Stdin:  strings.NewReader(""),
+				Stdout: stdout,
+				Stderr: stderr,
+			}, ExecId{
+				Id:   instance.GetId(),
+				Type: koyeb.EXECCOMMANDREQUESTIDTYPE_INSTANCE_ID,
+			}, command)
+			stdout.Flush()
+			stderr.Flush()
+
+			result := execInstanceResult{Instance: instance.GetId(), Region: instance.GetRegion(), ExitCode: code}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			reply.items[idx] = result
+		}()
+	}
+	wg.Wait()
+
+	ctx.Renderer.Render(reply)
+
+	if code := reply.exitCode(); code != 0 {
+		return &errors.CLIError{
+			What:       "Error while executing the command",
+			Why:        fmt.Sprintf("the command failed on %d of the %d instances of the service `%s`", reply.failures(), len(instances), serviceName),
+			Additional: []string{fmt.Sprintf("The exit code is the highest exit code of the instances: %d", code)},
+			Orig:       nil,
+			Solution:   "Check the output of the instances above",
+			ExitCode:   code,
+		}
+	}
+	return nil
+}
+
+// prefixWriter writes each line prefixed with prefix. Incomplete lines are
+// buffered until the next newline or the call to Flush.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		idx := bytes.IndexByte(p.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf[:idx+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[idx+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes the last line if it does not end with a newline.
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		_ = p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+	return err
+}
+
+type execInstanceResult struct {
+	Instance string `json:"instance_id"`
+	Region   string `json:"region"`
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
+
+type ExecAllInstancesReply struct {
+	items []execInstanceResult
+}
+
+// failures returns the number of instances where the command failed.
+func (r *ExecAllInstancesReply) failures() int {
+	ret := 0
+	for _, item := range r.items {
+		if item.ExitCode != 0 || item.Error != "" {
+			ret++
+		}
+	}
+	return ret
+}
+
+// exitCode returns the highest exit code of the instances. Instances where the
+// command could not be executed count as an exit code of 1.
+func (r *ExecAllInstancesReply) exitCode() int {
+	ret := 0
+	for _, item := range r.items {
+		code := item.ExitCode
+		if item.Error != "" || code < 0 {
+			code = max(code, 1)
+		}
+		ret = max(ret, code)
+	}
+	return ret
+}
+
+func (ExecAllInstancesReply) Title() string {
+	return "Instances"
+}
+
+func (r *ExecAllInstancesReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.items)
+}
+
+func (r *ExecAllInstancesReply) Headers() []string {
+	return []string{"instance", "region", "exit_code", "error"}
+}
+
+func (r *ExecAllInstancesReply) Fields() []map[string]string {
+	resp := []map[string]string{}
+	for _, item := range r.items {
+		resp = append(resp, map[string]string{
+			"instance":  renderer.FormatID(item.Instance, false),
+			"region":    item.Region,
+			"exit_code": strconv.Itoa(item.ExitCode),
+			"error":     item.Error,
+		})
+	}
+	return resp
+}
@@ -32,14 +32,29 @@ func (h *ServiceHandler) Get(ctx *CLIContext, cmd *cobra.Command, args []string)
 
 	full := GetBoolFlags(cmd, "full")
 	getServiceReply := NewGetServiceReply(ctx.Mapper, res, full)
+
+	// With -o definition, display the definition of the latest deployment
+	if _, ok := ctx.Renderer.(*renderer.DefinitionRenderer); ok {
+		deploymentRes, resp, err := ctx.Client.DeploymentsApi.GetDeployment(ctx.Context, res.Service.GetLatestDeploymentId()).Execute()
+		if err != nil {
+			return errors.NewCLIErrorFromAPIError(
+				fmt.Sprintf("Error while retrieving the latest deployment of the service `%s`", serviceName),
+				err,
+				resp,
+			)
+		}
+		getServiceReply.definition = deploymentRes.Deployment.Definition
+	}
+
 	ctx.Renderer.Render(getServiceReply)
 	return nil
 }
 
 type GetServiceReply struct {
-	mapper *idmapper.Mapper
-	value  *koyeb.GetServiceReply
-	full   bool
+	mapper     *idmapper.Mapper
+	value      *koyeb.GetServiceReply
+	full       bool
+	definition *koyeb.DeploymentDefinition
 }
 
 func NewGetServiceReply(mapper *idmapper.Mapper, value *koyeb.GetServiceReply, full bool) *GetServiceReply {
@@ -58,6 +73,15 @@ func (r *GetServiceReply) MarshalBinary() ([]byte, error) {
 	return r.value.GetService().MarshalJSON()
 }
 
+// MarshalDefinition implements renderer.DefinitionResource. The definition is
+// only set by `koyeb service get -o definition`.
+func (r *GetServiceReply) MarshalDefinition() ([]byte, error) {
+	if r.definition == nil {
+		return r.MarshalBinary()
+	}
+	return r.definition.MarshalJSON()
+}
+
 func (r *GetServiceReply) Headers() []string {
 	return []string{"id", "app", "name", "status", "created_at"}
 }
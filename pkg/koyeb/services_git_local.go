@@ -0,0 +1,84 @@
+package koyeb
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/gitrepo"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// isLocalGitPath returns true if the value of --git is a local directory
+// rather than a repository, for example "." or "../api".
+func isLocalGitPath(value string) bool {
+	return value == "." || value == ".." ||
+		strings.HasPrefix(value, "./") || strings.HasPrefix(value, "../") ||
+		strings.HasPrefix(value, `.\`) || strings.HasPrefix(value, `..\`) ||
+		filepath.IsAbs(value)
+}
+
+// setLocalGitSource configures the git source from the local repository
+// containing path: the repository is the GitHub remote, and the branch and the
+// commit are the current ones unless --git-branch or --git-sha are set. If path
+// is a sub-directory of the repository, it is used as the workdir unless
+// --git-workdir is set.
+func (h *ServiceHandler) setLocalGitSource(flags *pflag.FlagSet, source *koyeb.GitSource, path string) error {
+	repo, err := gitrepo.Open(path)
+	if err != nil {
+		return &errors.CLIError{
+			What:       "Error while reading the local git repository",
+			Why:        fmt.Sprintf("unable to read the git repository of %s", path),
+			Additional: []string{"When --git is a local directory, the repository, the branch and the commit are read from the git repository containing this directory."},
+			Orig:       err,
+			Solution:   "Run the command from a git repository, or provide the repository with --git github.com/<org>/<repository>",
+		}
+	}
+
+	repository, err := gitrepo.GitHubRepository(repo.RemoteURL)
+	if err != nil {
+		return &errors.CLIError{
+			What:       "Error while reading the local git repository",
+			Why:        fmt.Sprintf("unable to find the GitHub repository of the remote %s", repo.Remote),
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Push your code to GitHub, or provide the repository with --git github.com/<org>/<repository>",
+		}
+	}
+	source.SetRepository(repository)
+
+	if !flags.Lookup("git-branch").Changed {
+		if repo.RemoteBranch == "" {
+			return &errors.CLIError{
+				What:       "Error while reading the local git repository",
+				Why:        "HEAD is not on a branch",
+				Additional: nil,
+				Orig:       nil,
+				Solution:   "Checkout a branch, or provide the branch with --git-branch",
+			}
+		}
+		source.SetBranch(repo.RemoteBranch)
+	}
+
+	if !flags.Lookup("git-sha").Changed {
+		source.SetSha(repo.Head)
+		if !repo.IsPushed() {
+			log.Warnf("The commit %s is not pushed to %s/%s. Push it before deploying, otherwise the deployment will fail.", repo.Head[:min(7, len(repo.Head))], repo.Remote, repo.RemoteBranch)
+		}
+	}
+
+	if !flags.Lookup("git-workdir").Changed {
+		abs, err := filepath.Abs(path)
+		if err == nil {
+			if workdir, err := filepath.Rel(repo.Root, abs); err == nil && workdir != "." {
+				source.SetWorkdir(filepath.ToSlash(workdir))
+			}
+		}
+	}
+
+	log.Infof("Deploying %s, branch %s, commit %s", source.GetRepository(), source.GetBranch(), source.GetSha())
+	return nil
+}
@@ -0,0 +1,412 @@
+package koyeb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	koyeb_errors "github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// serviceWizard asks the user the settings of a new service, and sets the
+// flags of `koyeb service create` accordingly. The questions of the flags
+// already provided on the command line are skipped.
+type serviceWizard struct {
+	h     *ServiceHandler
+	ctx   *CLIContext
+	cmd   *cobra.Command
+	flags *pflag.FlagSet
+}
+
+// runCreateWizard runs the wizard of `koyeb service create --interactive`. It
+// returns the arguments of the command, and false if the user cancelled the
+// wizard.
+func (h *ServiceHandler) runCreateWizard(ctx *CLIContext, cmd *cobra.Command, args []string, definition *koyeb.DeploymentDefinition) ([]string, bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, false, &koyeb_errors.CLIError{
+			What:       "Unable to start interactive mode",
+			Why:        "the flag --interactive requires an interactive terminal.",
+			Additional: []string{"Make sure you are not piping the input of the command"},
+			Orig:       nil,
+			Solution:   "Remove the flag --interactive and configure the service with the flags of `koyeb service create`",
+		}
+	}
+
+	w := &serviceWizard{h: h, ctx: ctx, cmd: cmd, flags: cmd.Flags()}
+	args, err := w.run(args, definition)
+	if errors.Is(err, promptui.ErrInterrupt) || errors.Is(err, promptui.ErrEOF) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	log.Infof("To create the same service without the wizard, run: %s", equivalentCommand(cmd, args, "interactive"))
+	return args, true, nil
+}
+
+func (w *serviceWizard) run(args []string, definition *koyeb.DeploymentDefinition) ([]string, error) {
+	if len(args) == 0 && definition.GetName() == "" {
+		name, err := w.prompt("Service name", "", func(input string) error {
+			_, err := w.h.parseServiceName(w.cmd, input)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		args = []string{name}
+	}
+	if err := w.askApp(args); err != nil {
+		return nil, err
+	}
+
+	if err := w.choose("type", "Service type", []string{"web", "worker", "sandbox"}); err != nil {
+		return nil, err
+	}
+	if err := w.askSource(); err != nil {
+		return nil, err
+	}
+	if err := w.askInstanceType(); err != nil {
+		return nil, err
+	}
+	if err := w.ask("regions", "Regions, separated by commas", "was", func(flags *pflag.FlagSet) error {
+		_, err := w.h.parseRegions(flags, nil)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if w.serviceType() == koyeb.DEPLOYMENTDEFINITIONTYPE_WEB {
+		if err := w.ask("ports", "Ports exposed, as PORT[:PROTOCOL] separated by commas", "8000:http", func(flags *pflag.FlagSet) error {
+			_, err := w.h.parsePorts(w.serviceType(), flags, nil)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if err := w.ask("routes", "Routes, as PATH[:PORT] separated by commas", w.defaultRoute(), func(flags *pflag.FlagSet) error {
+			_, err := w.h.parseRoutes(w.serviceType(), flags, nil)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if err := w.ask("checks", "Health checks, as PORT:http:PATH or PORT:tcp separated by commas (leave empty for the default TCP checks)", "", func(flags *pflag.FlagSet) error {
+			_, err := w.h.parseChecks(w.serviceType(), flags, nil)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.askEnv(); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// askApp asks the application of the service, unless it is provided with
+// --app or in the service name.
+func (w *serviceWizard) askApp(args []string) error {
+	if w.flags.Lookup("app").Changed || len(args) == 0 {
+		return nil
+	}
+	if _, err := w.h.parseAppName(w.cmd, args[0]); err == nil {
+		return nil
+	}
+	apps, err := w.ctx.Mapper.App().Identifiers()
+	if err != nil || len(apps) == 0 {
+		app, err := w.prompt("Application", "", func(input string) error {
+			if input == "" {
+				return fmt.Errorf("the application name is required")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return w.flags.Set("app", app)
+	}
+	return w.choose("app", "Application", apps)
+}
+
+// askSource asks the source of the service: a docker image or a git
+// repository.
+func (w *serviceWizard) askSource() error {
+	hasSourceFlags := false
+	w.flags.Visit(func(flag *pflag.Flag) {
+		for _, prefix := range []string{"git", "docker", "archive"} {
+			if strings.HasPrefix(flag.Name, prefix) {
+				hasSourceFlags = true
+			}
+		}
+	})
+	if hasSourceFlags {
+		return nil
+	}
+
+	_, source, err := (&promptui.Select{
+		Label: "Source",
+		Items: []string{"docker image", "git repository"},
+	}).Run()
+	if err != nil {
+		return err
+	}
+
+	if source == "docker image" {
+		return w.ask("docker", "Docker image, for example koyeb/demo or ghcr.io/org/image:tag", "", func(flags *pflag.FlagSet) error {
+			if image, _ := flags.GetString("docker"); image == "" {
+				return fmt.Errorf("the docker image is required")
+			}
+			return nil
+		})
+	}
+
+	if err := w.ask("git", "Git repository, for example github.com/koyeb/example-flask", "", func(flags *pflag.FlagSet) error {
+		if repository, _ := flags.GetString("git"); repository == "" {
+			return fmt.Errorf("the git repository is required")
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := w.ask("git-branch", "Git branch", "main", nil); err != nil {
+		return err
+	}
+	if err := w.choose("git-builder", "Builder", []string{"buildpack", "docker"}); err != nil {
+		return err
+	}
+	if builder, _ := w.flags.GetString("git-builder"); builder == "docker" {
+		return w.ask("git-docker-dockerfile", "Dockerfile path (leave empty for the Dockerfile at the root of the repository)", "", nil)
+	}
+	if err := w.ask("git-buildpack-build-command", "Build command (leave empty to detect it)", "", nil); err != nil {
+		return err
+	}
+	return w.ask("git-buildpack-run-command", "Run command (leave empty to detect it)", "", nil)
+}
+
+// askInstanceType lets the user choose an instance type of the catalog. If the
+// catalog cannot be fetched, the instance type is asked as free text.
+func (w *serviceWizard) askInstanceType() error {
+	if w.flags.Lookup("instance-type").Changed {
+		return nil
+	}
+	instanceTypes, err := completeInstanceTypes(w.ctx, w.cmd)
+	if err != nil || len(instanceTypes) == 0 {
+		return w.ask("instance-type", "Instance type", w.flags.Lookup("instance-type").DefValue, nil)
+	}
+
+	items := []string{}
+	cursor := 0
+	for idx, instanceType := range instanceTypes {
+		id, description, _ := strings.Cut(instanceType, "\t")
+		if id == w.flags.Lookup("instance-type").DefValue {
+			cursor = idx
+		}
+		items = append(items, fmt.Sprintf("%s (%s)", id, description))
+	}
+	idx, _, err := (&promptui.Select{
+		Label:     "Instance type",
+		Items:     items,
+		Size:      10,
+		CursorPos: cursor,
+	}).Run()
+	if err != nil {
+		return err
+	}
+	id, _, _ := strings.Cut(instanceTypes[idx], "\t")
+	return w.flags.Set("instance-type", id)
+}
+
+// askEnv asks the environment variables, one per prompt, until the user
+// enters an empty line.
+func (w *serviceWizard) askEnv() error {
+	if w.flags.Lookup("env").Changed {
+		return nil
+	}
+	for {
+		env, err := w.prompt("Environment variable, as KEY=VALUE or KEY={{secret.NAME}} (leave empty to continue)", "", func(input string) error {
+			if input == "" {
+				return nil
+			}
+			return w.validate("env", csvQuote(input), func(flags *pflag.FlagSet) error {
+				_, err := w.h.parseEnv(flags, nil)
+				return err
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if env == "" {
+			return nil
+		}
+		// The values of string slice flags are appended after the first call to
+		// Set, and parsed as CSV
+		if err := w.flags.Set("env", csvQuote(env)); err != nil {
+			return err
+		}
+	}
+}
+
+// ask prompts the value of a flag, unless it has been provided on the command
+// line. The answer is validated by parse, called with a new set of service
+// definition flags where only this flag is set. Empty answers leave the flag
+// unset.
+func (w *serviceWizard) ask(name string, label string, def string, parse func(*pflag.FlagSet) error) error {
+	if w.flags.Lookup(name).Changed {
+		return nil
+	}
+	value, err := w.prompt(label, def, func(input string) error {
+		if input == "" || parse == nil {
+			return nil
+		}
+		return w.validate(name, input, parse)
+	})
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return nil
+	}
+	return w.flags.Set(name, w.normalize(name, value))
+}
+
+// choose lets the user select the value of a flag among items, unless it has
+// been provided on the command line.
+func (w *serviceWizard) choose(name string, label string, items []string) error {
+	if w.flags.Lookup(name).Changed {
+		return nil
+	}
+	cursor := 0
+	for idx, item := range items {
+		if item == w.flags.Lookup(name).DefValue {
+			cursor = idx
+		}
+	}
+	_, value, err := (&promptui.Select{Label: label, Items: items, CursorPos: cursor}).Run()
+	if err != nil {
+		return err
+	}
+	return w.flags.Set(name, value)
+}
+
+func (w *serviceWizard) prompt(label string, def string, validate promptui.ValidateFunc) (string, error) {
+	value, err := (&promptui.Prompt{
+		Label:     label,
+		Default:   def,
+		AllowEdit: true,
+		Validate:  validate,
+	}).Run()
+	return strings.TrimSpace(value), err
+}
+
+// validate sets the flag name to value on a new set of service definition
+// flags, and calls parse. The errors are reduced to one line, to be displayed
+// under the prompt.
+func (w *serviceWizard) validate(name string, value string, parse func(*pflag.FlagSet) error) error {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	w.h.addServiceDefinitionFlags(flags)
+	if name != "type" {
+		flags.Set("type", string(w.serviceType())) //nolint:errcheck
+	}
+	err := flags.Set(name, w.normalize(name, value))
+	if err == nil {
+		err = parse(flags)
+	}
+	var cliErr *koyeb_errors.CLIError
+	if errors.As(err, &cliErr) {
+		return errors.New(cliErr.Why)
+	}
+	return err
+}
+
+// normalize removes the spaces around the commas of the answers to the
+// questions of string slice flags, like "8000:http, 9000:tcp".
+func (w *serviceWizard) normalize(name string, value string) string {
+	if _, ok := w.flags.Lookup(name).Value.(pflag.SliceValue); !ok || strings.Contains(value, `"`) {
+		return value
+	}
+	items := strings.Split(value, ",")
+	for idx := range items {
+		items[idx] = strings.TrimSpace(items[idx])
+	}
+	return strings.Join(items, ",")
+}
+
+// csvQuote quotes value to be set as a single item of a string slice flag.
+func csvQuote(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+func (w *serviceWizard) serviceType() koyeb.DeploymentDefinitionType {
+	type_, err := w.h.parseType(w.flags, koyeb.DEPLOYMENTDEFINITIONTYPE_INVALID)
+	if err != nil {
+		return koyeb.DEPLOYMENTDEFINITIONTYPE_WEB
+	}
+	return type_
+}
+
+// defaultRoute returns the route "/" to the first port answered.
+func (w *serviceWizard) defaultRoute() string {
+	ports, err := w.h.parsePorts(w.serviceType(), w.flags, nil)
+	if err != nil || len(ports) == 0 {
+		return "/:8000"
+	}
+	return fmt.Sprintf("/:%d", ports[0].GetPort())
+}
+
+// equivalentCommand returns the command line running cmd with args and the
+// flags set, except the flags listed in skip. Persistent flags, like --token,
+// are never included.
+func equivalentCommand(cmd *cobra.Command, args []string, skip ...string) string {
+	parts := []string{cmd.CommandPath()}
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	// LocalNonPersistentFlags returns a new flag set, where Visit does not
+	// return the flags set
+	cmd.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		for _, name := range skip {
+			if flag.Name == name {
+				return
+			}
+		}
+		switch value := flag.Value.(type) {
+		case pflag.SliceValue:
+			for _, item := range value.GetSlice() {
+				// The values of string slice flags are parsed as CSV
+				if strings.ContainsAny(item, `,"`) {
+					item = csvQuote(item)
+				}
+				parts = append(parts, "--"+flag.Name, shellQuote(item))
+			}
+		default:
+			if flag.Value.Type() == "bool" && flag.Value.String() == "true" {
+				parts = append(parts, "--"+flag.Name)
+			} else if flag.Value.Type() == "bool" {
+				parts = append(parts, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+			} else {
+				parts = append(parts, "--"+flag.Name, shellQuote(flag.Value.String()))
+			}
+		}
+	})
+	return strings.Join(parts, " ")
+}
+
+var shellSafeRegexp = regexp.MustCompile(`^[a-zA-Z0-9_./:=@,+-]+$`)
+
+// shellQuote quotes s to be used as a single argument in a POSIX shell.
+func shellQuote(s string) string {
+	if shellSafeRegexp.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,45 @@
+package koyeb
+
+import (
+	"fmt"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// PortForward forwards the ports to a healthy instance of the service. The
+// instance is picked once, so all the connections reach the same instance.
+func (h *ServiceHandler) PortForward(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	serviceName, err := h.parseServiceName(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	service, err := h.ResolveServiceArgs(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	instances, err := h.listInstances(ctx, service, serviceName, koyeb.INSTANCESTATUS_HEALTHY)
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		return &errors.CLIError{
+			What:       "Error while forwarding ports",
+			Why:        fmt.Sprintf("the service `%s` has no healthy instance", serviceName),
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Wait for the service to be healthy, or check its status with koyeb service describe",
+		}
+	}
+
+	instance := instances[0]
+	log.Infof("Forwarding ports to the instance %s (%s)", instance.GetId(), instance.GetRegion())
+	return PortForward(ctx, cmd, ExecId{
+		Id:   instance.GetId(),
+		Type: koyeb.EXECCOMMANDREQUESTIDTYPE_INSTANCE_ID,
+	}, args[1:])
+}
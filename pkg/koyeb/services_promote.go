@@ -0,0 +1,285 @@
+package koyeb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/yudai/gojsondiff"
+)
+
+func (h *ServiceHandler) Promote(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	sourceName, err := h.parseServiceName(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	targetName, err := h.parseServiceName(cmd, args[1])
+	if err != nil {
+		return err
+	}
+
+	source, err := h.ResolveServiceArgs(ctx, sourceName)
+	if err != nil {
+		return err
+	}
+	target, err := h.ResolveServiceArgs(ctx, targetName)
+	if err != nil {
+		return err
+	}
+	if source == target {
+		return &errors.CLIError{
+			What:       "Error while promoting the service",
+			Why:        "the source and the target are the same service",
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Use `koyeb service redeploy` to redeploy a service",
+		}
+	}
+
+	sourceDeploy, err := h.getLatestHealthyDeployment(ctx, source, sourceName)
+	if err != nil {
+		return err
+	}
+	targetDeploy, err := h.getLatestDeployment(ctx, target, targetName)
+	if err != nil {
+		return err
+	}
+
+	useBuiltImage := GetBoolFlags(cmd, "use-built-image")
+	definition := targetDeploy.GetDefinition()
+	lhs, _ := json.Marshal(definition)
+	if err := setPromotedSource(&definition, sourceDeploy, useBuiltImage); err != nil {
+		return err
+	}
+	rhs, _ := json.Marshal(definition)
+
+	// The image built for a git or archive source is stored in the registry of
+	// Koyeb, which might not be pullable by the target
+	if sourceDefinition := sourceDeploy.GetDefinition(); useBuiltImage && !sourceDefinition.HasDocker() {
+		if err := h.checkDockerImage(ctx, definition.Docker); err != nil {
+			return &errors.CLIError{
+				What:       "Error while promoting the service",
+				Why:        fmt.Sprintf("the image built for the deployment %s can not be pulled", sourceDeploy.GetId()[:8]),
+				Additional: nil,
+				Orig:       err,
+				Solution:   "Promote the service without --use-built-image to deploy the commit or the archive of SOURCE and build it again",
+			}
+		}
+	}
+
+	diff, err := gojsondiff.New().Compare(lhs, rhs)
+	if err != nil {
+		return &errors.CLIError{
+			What:       "Error while promoting the service",
+			Why:        "unable to create the JSON diff",
+			Additional: []string{},
+			Orig:       err,
+			Solution:   "Please, create an issue on https://github.com/koyeb/koyeb-cli/issues/new and provide your service ID",
+		}
+	}
+	if !diff.Modified() {
+		log.Infof("The service `%s` already runs the source of the deployment %s of `%s`, nothing to promote.", targetName, sourceDeploy.GetId()[:8], sourceName)
+		return nil
+	}
+	ctx.Renderer.Render(&PromoteServiceDiff{NewShowDeploymentsDiff(diff, lhs)})
+
+	if GetBoolFlags(cmd, "dry-run") {
+		log.Infof("Dry run: the changes have not been deployed. Run the command without --dry-run to deploy them.")
+		return nil
+	}
+
+	currentService, resp, err := ctx.Client.ServicesApi.GetService(ctx.Context, target).Execute()
+	if err != nil {
+		return errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while fetching service `%s`", targetName),
+			err,
+			resp,
+		)
+	}
+
+	updateService := koyeb.NewUpdateServiceWithDefaults()
+	updateService.SetDefinition(definition)
+	if currentService.Service.HasLifeCycle() {
+		updateService.SetLifeCycle(currentService.Service.GetLifeCycle())
+	}
+
+	log.Infof("Promoting the deployment %s of `%s` to `%s`", sourceDeploy.GetId()[:8], sourceName, targetName)
+	return h.Update(ctx, cmd, []string{targetName}, updateService)
+}
+
+// getLatestHealthyDeployment returns the most recent deployment of the service which is healthy.
+func (h *ServiceHandler) getLatestHealthyDeployment(ctx *CLIContext, serviceID string, serviceName string) (*koyeb.DeploymentListItem, error) {
+	res, resp, err := ctx.Client.DeploymentsApi.
+		ListDeployments(ctx.Context).
+		ServiceId(serviceID).
+		Statuses([]string{string(koyeb.DEPLOYMENTSTATUS_HEALTHY)}).
+		Limit("1").
+		Execute()
+	if err != nil {
+		return nil, errors.NewCLIErrorFromAPIError(
+			fmt.Sprintf("Error while listing the deployments of the service `%s`", serviceName),
+			err,
+			resp,
+		)
+	}
+	if len(res.GetDeployments()) == 0 {
+		return nil, &errors.CLIError{
+			What:       fmt.Sprintf("Error while promoting the service `%s`", serviceName),
+			Why:        "the service has no healthy deployment",
+			Additional: nil,
+			Orig:       nil,
+			Solution:   errors.CLIErrorSolution(fmt.Sprintf("Wait for the service to be healthy with `koyeb wait service %s --for status=HEALTHY`, then try again", serviceName)),
+		}
+	}
+	return &res.GetDeployments()[0], nil
+}
+
+// setPromotedSource replaces the source of the definition with the exact
+// source deployed by the deployment: the Docker image is pinned to its digest,
+// git sources to the deployed commit, and archive sources to the deployed
+// archive, which the target builds again. With useBuiltImage, git and archive
+// sources are replaced by a Docker source deploying the image built for the
+// deployment, so the target runs the same artifact without building it.
+func setPromotedSource(definition *koyeb.DeploymentDefinition, deployment *koyeb.DeploymentListItem, useBuiltImage bool) error {
+	source := deployment.GetDefinition()
+	provisioning := deployment.GetProvisioningInfo()
+
+	definition.Docker = nil
+	definition.Git = nil
+	definition.Archive = nil
+
+	switch {
+	case source.HasDocker():
+		docker := source.GetDocker()
+		image := pinDockerImage(docker.GetImage(), provisioning.GetImage(), provisioning.GetSha())
+		if image == docker.GetImage() && !strings.Contains(image, "@") {
+			log.Warnf("Unable to find the digest of the image %s deployed by %s. The image is promoted by tag, which might point to another image if it has been pushed again.", image, deployment.GetId()[:8])
+		}
+		docker.SetImage(image)
+		definition.SetDocker(docker)
+		return nil
+	case !source.HasGit() && !source.HasArchive():
+		return &errors.CLIError{
+			What:       "Error while promoting the service",
+			Why:        fmt.Sprintf("the deployment %s has no Docker, git or archive source", deployment.GetId()[:8]),
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Only services deployed from a Docker image, a git repository or an archive can be promoted",
+		}
+	case useBuiltImage:
+		docker, err := builtImageSource(&source, provisioning, deployment.GetId())
+		if err != nil {
+			return err
+		}
+		definition.SetDocker(*docker)
+		return nil
+	case source.HasGit():
+		git := source.GetGit()
+		sha := provisioning.GetSha()
+		if sha == "" {
+			sha = git.GetSha()
+		}
+		if sha == "" {
+			return &errors.CLIError{
+				What:       "Error while promoting the service",
+				Why:        fmt.Sprintf("unable to find the commit deployed by %s", deployment.GetId()[:8]),
+				Additional: nil,
+				Orig:       nil,
+				Solution:   "Try again in a few seconds. If the problem persists, please create an issue on https://github.com/koyeb/koyeb-cli/issues/new",
+			}
+		}
+		git.SetSha(sha)
+		definition.SetGit(git)
+	default:
+		definition.SetArchive(source.GetArchive())
+	}
+	log.Infof("The target builds the source of %s again, and might not run the same image. Use --use-built-image to deploy the image built for %s instead.", deployment.GetId()[:8], deployment.GetId()[:8])
+	return nil
+}
+
+// builtImageSource returns a Docker source deploying the image built for a
+// deployment of a git or archive source, with the command of its builder.
+func builtImageSource(source *koyeb.DeploymentDefinition, provisioning koyeb.DeploymentProvisioningInfo, deploymentID string) (*koyeb.DockerSource, error) {
+	image := provisioning.GetImage()
+	if image == "" {
+		return nil, &errors.CLIError{
+			What: "Error while promoting the service",
+			Why:  fmt.Sprintf("unable to find the image built for the deployment %s", deploymentID[:8]),
+			Additional: []string{
+				"With --use-built-image, services deployed from git or from an archive are promoted with the image built for their deployment, so the target runs the same artifact.",
+			},
+			Orig:     nil,
+			Solution: "Promote the service without --use-built-image to deploy the commit or the archive and build it again, or try again in a few seconds",
+		}
+	}
+
+	var buildpack *koyeb.BuildpackBuilder
+	var builder *koyeb.DockerBuilder
+	if source.HasGit() {
+		git := source.GetGit()
+		buildpack, builder = git.Buildpack, git.Docker
+		if buildpack == nil && builder == nil && git.GetRunCommand() != "" {
+			// Legacy git sources set the run command on the source
+			buildpack = &koyeb.BuildpackBuilder{RunCommand: git.RunCommand}
+		}
+	} else {
+		archive := source.GetArchive()
+		buildpack, builder = archive.Buildpack, archive.Docker
+	}
+
+	docker := koyeb.NewDockerSourceWithDefaults()
+	docker.SetImage(pinDockerImage(image, image, provisioning.GetSha()))
+	switch {
+	case builder != nil:
+		docker.Entrypoint = builder.Entrypoint
+		docker.Command = builder.Command
+		docker.Args = builder.Args
+		docker.Privileged = builder.Privileged
+	case buildpack != nil:
+		if buildpack.GetRunCommand() != "" {
+			docker.SetCommand(buildpack.GetRunCommand())
+		}
+		docker.Privileged = buildpack.Privileged
+	}
+	return docker, nil
+}
+
+// pinDockerImage returns the image reference pinned to a digest. provisioned
+// is the image resolved when the deployment was provisioned, and sha the
+// digest reported by the provisioning, if any. If no digest is known, image is
+// returned unchanged.
+func pinDockerImage(image string, provisioned string, sha string) string {
+	if strings.Contains(image, "@") {
+		return image
+	}
+	if strings.Contains(provisioned, "@sha256:") {
+		return provisioned
+	}
+	if strings.HasPrefix(sha, "sha256:") {
+		return dockerImageWithoutTag(image) + "@" + sha
+	}
+	return image
+}
+
+// dockerImageWithoutTag removes the tag of an image reference. The tag follows
+// the last colon, unless this colon is part of the registry host, for example
+// in localhost:5000/image.
+func dockerImageWithoutTag(image string) string {
+	idx := strings.LastIndex(image, ":")
+	if idx < 0 || strings.Contains(image[idx:], "/") {
+		return image
+	}
+	return image[:idx]
+}
+
+type PromoteServiceDiff struct {
+	*ShowDeploymentsDiff
+}
+
+func (PromoteServiceDiff) Title() string {
+	return "Promoted changes"
+}
@@ -24,7 +24,8 @@ func (h *ServiceHandler) ReDeploy(ctx *CLIContext, cmd *cobra.Command, args []st
 
 	useCache := GetBoolFlags(cmd, "use-cache")
 	skipBuild := GetBoolFlags(cmd, "skip-build")
-	wait := GetBoolFlags(cmd, "wait")
+	// --logs implies --wait
+	wait := GetBoolFlags(cmd, "wait") || GetBoolFlags(cmd, "logs")
 	waitTimeout := GetDurationFlags(cmd, "wait-timeout")
 
 	redeployBody := *koyeb.NewRedeployRequestInfoWithDefaults()
@@ -48,6 +49,11 @@ func (h *ServiceHandler) ReDeploy(ctx *CLIContext, cmd *cobra.Command, args []st
 		ctxd, cancel := context.WithTimeout(ctx.Context, waitTimeout)
 		defer cancel()
 
+		if GetBoolFlags(cmd, "logs") {
+			follower := FollowDeploymentLogs(ctx, res.Deployment.GetId(), GetBoolFlags(cmd, "full"))
+			defer follower.Stop()
+		}
+
 		for range ticker(ctxd, 2*time.Second) {
 			res, resp, err := ctx.Client.DeploymentsApi.GetDeployment(ctxd, res.Deployment.GetId()).Execute()
 			if err != nil {
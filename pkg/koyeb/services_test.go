@@ -1,10 +1,21 @@
 package koyeb
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/dotenv"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/flags_list"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -646,3 +657,455 @@ func TestSetSleepDelayFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadDeploymentDefinitionFile(t *testing.T) {
+	tests := map[string]struct {
+		content     string
+		expected    *koyeb.DeploymentDefinition
+		expectedErr bool
+	}{
+		"yaml": {
+			content: "name: api\ntype: WORKER\ndocker:\n  image: nginx\nregions: [fra]\n",
+			expected: &koyeb.DeploymentDefinition{
+				Name:    koyeb.PtrString("api"),
+				Type:    koyeb.DEPLOYMENTDEFINITIONTYPE_WORKER.Ptr(),
+				Docker:  &koyeb.DockerSource{Image: koyeb.PtrString("nginx")},
+				Regions: []string{"fra"},
+			},
+		},
+		"json_without_type": {
+			content: `{"name": "api", "env": [{"key": "FOO", "value": "bar"}]}`,
+			expected: &koyeb.DeploymentDefinition{
+				Name: koyeb.PtrString("api"),
+				Type: koyeb.DEPLOYMENTDEFINITIONTYPE_INVALID.Ptr(),
+				Env:  []koyeb.DeploymentEnv{{Key: koyeb.PtrString("FOO"), Value: koyeb.PtrString("bar")}},
+			},
+		},
+		"unknown_key": {
+			content:     "name: api\nregion: fra\n",
+			expectedErr: true,
+		},
+		"invalid_syntax": {
+			content:     "name: [api\n",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "svc.yaml")
+			assert.NoError(t, os.WriteFile(path, []byte(tc.content), 0600))
+
+			definition, err := loadDeploymentDefinitionFile(path)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, definition)
+			}
+		})
+	}
+}
+
+func TestPinDockerImage(t *testing.T) {
+	tests := map[string]struct {
+		image       string
+		provisioned string
+		sha         string
+		expected    string
+	}{
+		"already_pinned": {
+			image:       "koyeb/demo@sha256:abc",
+			provisioned: "docker.io/koyeb/demo@sha256:def",
+			expected:    "koyeb/demo@sha256:abc",
+		},
+		"provisioned_digest": {
+			image:       "koyeb/demo:latest",
+			provisioned: "docker.io/koyeb/demo@sha256:def",
+			expected:    "docker.io/koyeb/demo@sha256:def",
+		},
+		"sha": {
+			image:    "koyeb/demo:latest",
+			sha:      "sha256:def",
+			expected: "koyeb/demo@sha256:def",
+		},
+		"sha_registry_with_port": {
+			image:    "localhost:5000/demo",
+			sha:      "sha256:def",
+			expected: "localhost:5000/demo@sha256:def",
+		},
+		"unknown_digest": {
+			image:    "koyeb/demo:latest",
+			expected: "koyeb/demo:latest",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, pinDockerImage(tc.image, tc.provisioned, tc.sha))
+		})
+	}
+}
+
+func TestSetPromotedSource(t *testing.T) {
+	const digest = "sha256:0123456789abcdef"
+	built := koyeb.DeploymentProvisioningInfo{
+		Image: koyeb.PtrString("registry.koyeb.com/org/app-api"),
+		Sha:   koyeb.PtrString(digest),
+	}
+
+	tests := map[string]struct {
+		source       koyeb.DeploymentDefinition
+		provisioning koyeb.DeploymentProvisioningInfo
+		builtImage   bool
+		expected     koyeb.DeploymentDefinition
+		err          bool
+	}{
+		"docker": {
+			source:       koyeb.DeploymentDefinition{Docker: &koyeb.DockerSource{Image: koyeb.PtrString("nginx:1.25")}},
+			provisioning: koyeb.DeploymentProvisioningInfo{Sha: koyeb.PtrString(digest)},
+			expected:     koyeb.DeploymentDefinition{Docker: &koyeb.DockerSource{Image: koyeb.PtrString("nginx@" + digest)}},
+		},
+		"git_buildpack": {
+			source: koyeb.DeploymentDefinition{Git: &koyeb.GitSource{
+				Repository: koyeb.PtrString("github.com/org/repo"),
+				Buildpack:  &koyeb.BuildpackBuilder{RunCommand: koyeb.PtrString("gunicorn app:app")},
+			}},
+			provisioning: built,
+			builtImage:   true,
+			expected: koyeb.DeploymentDefinition{Docker: &koyeb.DockerSource{
+				Image:   koyeb.PtrString("registry.koyeb.com/org/app-api@" + digest),
+				Command: koyeb.PtrString("gunicorn app:app"),
+			}},
+		},
+		"archive_docker": {
+			source: koyeb.DeploymentDefinition{Archive: &koyeb.ArchiveSource{
+				Id:     koyeb.PtrString("archive"),
+				Docker: &koyeb.DockerBuilder{Command: koyeb.PtrString("serve"), Args: []string{"--port", "80"}},
+			}},
+			provisioning: built,
+			builtImage:   true,
+			expected: koyeb.DeploymentDefinition{Docker: &koyeb.DockerSource{
+				Image:   koyeb.PtrString("registry.koyeb.com/org/app-api@" + digest),
+				Command: koyeb.PtrString("serve"),
+				Args:    []string{"--port", "80"},
+			}},
+		},
+		"git_no_built_image": {
+			source:     koyeb.DeploymentDefinition{Git: &koyeb.GitSource{Repository: koyeb.PtrString("github.com/org/repo")}},
+			builtImage: true,
+			err:        true,
+		},
+		"git_no_commit": {
+			source: koyeb.DeploymentDefinition{Git: &koyeb.GitSource{Repository: koyeb.PtrString("github.com/org/repo")}},
+			err:    true,
+		},
+		"git": {
+			source:       koyeb.DeploymentDefinition{Git: &koyeb.GitSource{Repository: koyeb.PtrString("github.com/org/repo")}},
+			provisioning: koyeb.DeploymentProvisioningInfo{Sha: koyeb.PtrString("abc123")},
+			expected: koyeb.DeploymentDefinition{Git: &koyeb.GitSource{
+				Repository: koyeb.PtrString("github.com/org/repo"),
+				Sha:        koyeb.PtrString("abc123"),
+			}},
+		},
+		"archive": {
+			source:   koyeb.DeploymentDefinition{Archive: &koyeb.ArchiveSource{Id: koyeb.PtrString("archive")}},
+			expected: koyeb.DeploymentDefinition{Archive: &koyeb.ArchiveSource{Id: koyeb.PtrString("archive")}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			deployment := &koyeb.DeploymentListItem{
+				Id:               koyeb.PtrString("44444444-4444-4444-8444-444444444444"),
+				Definition:       &tc.source,
+				ProvisioningInfo: &tc.provisioning,
+			}
+			definition := koyeb.DeploymentDefinition{Git: &koyeb.GitSource{Repository: koyeb.PtrString("github.com/org/other")}}
+			err := setPromotedSource(&definition, deployment, tc.builtImage)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, definition)
+		})
+	}
+}
+
+func TestCloneDefinition(t *testing.T) {
+	h := NewServiceHandler()
+
+	source := koyeb.DeploymentDefinition{
+		Name:    koyeb.PtrString("api"),
+		Regions: []string{"fra", "was"},
+		Env: []koyeb.DeploymentEnv{
+			{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("staging"), Scopes: []string{"region:fra", "region:was"}},
+		},
+		InstanceTypes: []koyeb.DeploymentInstanceType{
+			{Type: koyeb.PtrString("small"), Scopes: []string{"region:fra", "region:was"}},
+		},
+		Volumes: []koyeb.DeploymentVolume{
+			{Id: koyeb.PtrString("volume-id"), Path: koyeb.PtrString("/data"), Scopes: []string{"region:fra"}},
+		},
+	}
+
+	opts := &cloneOptions{
+		regions:       map[string]string{"fra": "was"},
+		instanceTypes: map[string]string{"small": "medium"},
+		volumes:       "skip",
+	}
+	opts.env, _ = flags_list.NewEnvListFromFlags([]string{"ENV=production"})
+
+	clone, err := h.cloneDefinition(source, "api-clone", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "api-clone", clone.GetName())
+	assert.Equal(t, []string{"was"}, clone.GetRegions())
+	assert.Equal(t, []koyeb.DeploymentEnv{
+		{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("production"), Scopes: []string{"region:was"}},
+	}, clone.GetEnv())
+	assert.Equal(t, []koyeb.DeploymentInstanceType{
+		{Type: koyeb.PtrString("medium"), Scopes: []string{"region:was"}},
+	}, clone.GetInstanceTypes())
+	assert.Empty(t, clone.Volumes)
+
+	// The source is not modified
+	assert.Equal(t, []string{"fra", "was"}, source.GetRegions())
+	assert.Equal(t, "small", source.InstanceTypes[0].GetType())
+}
+
+func TestCloneDefinitionScaleToZero(t *testing.T) {
+	h := NewServiceHandler()
+
+	for name, tc := range map[string]struct {
+		type_    koyeb.DeploymentDefinitionType
+		expected int64
+	}{
+		"web":    {type_: koyeb.DEPLOYMENTDEFINITIONTYPE_WEB, expected: 0},
+		"worker": {type_: koyeb.DEPLOYMENTDEFINITIONTYPE_WORKER, expected: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			source := koyeb.DeploymentDefinition{
+				Type:     tc.type_.Ptr(),
+				Scalings: []koyeb.DeploymentScaling{{Min: koyeb.PtrInt64(2), Max: koyeb.PtrInt64(4)}},
+			}
+			clone, err := h.cloneDefinition(source, "api-preview", &cloneOptions{volumes: "skip", scaleToZero: true})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, clone.Scalings[0].GetMin())
+			assert.Equal(t, int64(4), clone.Scalings[0].GetMax())
+			assert.Equal(t, int64(2), source.Scalings[0].GetMin())
+		})
+	}
+}
+
+func TestEquivalentCommand(t *testing.T) {
+	h := NewServiceHandler()
+	root := &cobra.Command{Use: "koyeb"}
+	root.PersistentFlags().String("token", "", "")
+	services := &cobra.Command{Use: "services"}
+	cmd := &cobra.Command{Use: "create", Run: func(*cobra.Command, []string) {}}
+	h.addServiceDefinitionFlags(cmd.Flags())
+	cmd.Flags().StringP("app", "a", "", "")
+	cmd.Flags().Bool("wait", false, "")
+	cmd.Flags().BoolP("interactive", "i", false, "")
+	services.AddCommand(cmd)
+	root.AddCommand(services)
+
+	root.SetArgs([]string{
+		"services", "create", "my service", "--token", "secret", "--interactive", "--app", "myapp", "--wait",
+		"--docker", "nginx", "--env", "A=1", "--env", "B=it's", "--env", `"C=a,b"`, "--port", "80:http",
+	})
+	assert.NoError(t, root.Execute())
+
+	assert.Equal(t,
+		`koyeb services create 'my service' --app myapp --docker nginx --env A=1 --env 'B=it'\''s' --env '"C=a,b"' --ports 80:http --wait`,
+		equivalentCommand(cmd, []string{"my service"}, "interactive"),
+	)
+}
+
+func TestServiceWizardValidate(t *testing.T) {
+	h := NewServiceHandler()
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	h.addServiceDefinitionFlags(flags)
+	w := &serviceWizard{h: h, flags: flags}
+
+	parsePorts := func(flags *pflag.FlagSet) error {
+		_, err := h.parsePorts(w.serviceType(), flags, nil)
+		return err
+	}
+	assert.NoError(t, w.validate("ports", "8000:http, 9000:tcp", parsePorts))
+	assert.EqualError(t, w.validate("ports", "abc", parsePorts), `unable to parse the port "abc"`)
+
+	// Ports are only valid for web services
+	assert.NoError(t, flags.Set("type", "worker"))
+	assert.EqualError(t, w.validate("ports", "8000", parsePorts), `your service has ports configured, which is only possible for services of type "web"`)
+}
+
+func TestUpdateEnvRegionScopes(t *testing.T) {
+	h := NewServiceHandler()
+
+	for name, tc := range map[string]struct {
+		flags    []string
+		expected []koyeb.DeploymentEnv
+	}{
+		"set_replaces_all_regions": {
+			flags: []string{"ENV=production"},
+			expected: []koyeb.DeploymentEnv{
+				{Key: koyeb.PtrString("PORT"), Value: koyeb.PtrString("8000"), Scopes: []string{"region:fra", "region:was"}},
+				{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("production"), Scopes: []string{"region:fra", "region:was"}},
+			},
+		},
+		"unset_removes_all_regions": {
+			flags: []string{"!ENV"},
+			expected: []koyeb.DeploymentEnv{
+				{Key: koyeb.PtrString("PORT"), Value: koyeb.PtrString("8000"), Scopes: []string{"region:fra", "region:was"}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			definition := &koyeb.DeploymentDefinition{
+				Regions: []string{"fra", "was"},
+				Env: []koyeb.DeploymentEnv{
+					{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("europe"), Scopes: []string{"region:fra"}},
+					{Key: koyeb.PtrString("PORT"), Value: koyeb.PtrString("8000"), Scopes: []string{"region:fra", "region:was"}},
+					{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("america"), Scopes: []string{"region:was"}},
+				},
+			}
+			flags, err := flags_list.NewEnvListFromFlags(tc.flags)
+			assert.NoError(t, err)
+
+			definition.SetEnv(flags_list.ReplaceListFlags(flags, definition.GetEnv()))
+			h.setRegions(definition, definition.GetRegions())
+			env := definition.GetEnv()
+			assert.Len(t, env, len(tc.expected))
+			for idx := range tc.expected {
+				assert.Equal(t, tc.expected[idx].GetKey(), env[idx].GetKey())
+				assert.Equal(t, tc.expected[idx].GetValue(), env[idx].GetValue())
+				assert.ElementsMatch(t, tc.expected[idx].Scopes, env[idx].Scopes)
+			}
+		})
+	}
+}
+
+func TestRecreateVolumesDeletesOnFailure(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/v1/volumes/")
+			json.NewEncoder(w).Encode(koyeb.GetPersistentVolumeReply{Volume: &koyeb.PersistentVolume{ //nolint:errcheck
+				Id:     koyeb.PtrString(id),
+				Name:   koyeb.PtrString(id),
+				Region: koyeb.PtrString("fra"),
+			}})
+		case http.MethodPost:
+			var body koyeb.CreatePersistentVolumeRequest
+			json.NewDecoder(r.Body).Decode(&body) //nolint:errcheck
+			if body.GetName() == "app-api-cache" {
+				http.Error(w, `{"message": "quota exceeded"}`, http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(koyeb.CreatePersistentVolumeReply{Volume: &koyeb.PersistentVolume{ //nolint:errcheck
+				Id:   koyeb.PtrString("new-" + body.GetName()),
+				Name: body.Name,
+			}})
+		case http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/v1/volumes/"))
+			json.NewEncoder(w).Encode(map[string]any{}) //nolint:errcheck
+		}
+	}))
+	defer server.Close()
+
+	config := koyeb.NewConfiguration()
+	config.Servers[0].URL = server.URL
+	ctx := &CLIContext{Context: context.Background(), Client: koyeb.NewAPIClient(config)}
+
+	definition := &koyeb.DeploymentDefinition{Volumes: []koyeb.DeploymentVolume{
+		{Id: koyeb.PtrString("data"), Path: koyeb.PtrString("/data")},
+		{Id: koyeb.PtrString("cache"), Path: koyeb.PtrString("/cache")},
+	}}
+	volumes, err := NewServiceHandler().recreateVolumes(ctx, definition, "app-api-", &cloneOptions{}, NewCloneReply(false))
+	assert.Error(t, err)
+	assert.Nil(t, volumes)
+	// The volume created before the failure is deleted
+	assert.Equal(t, []string{"new-app-api-data"}, deleted)
+}
+
+func TestEnvToDotenvVariables(t *testing.T) {
+	tests := map[string]struct {
+		env      []koyeb.DeploymentEnv
+		expected []dotenv.Variable
+		warning  bool
+	}{
+		"same_value_per_region": {
+			env: []koyeb.DeploymentEnv{
+				{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("production"), Scopes: []string{"region:fra"}},
+				{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("production"), Scopes: []string{"region:was"}},
+				{Key: koyeb.PtrString("TOKEN"), Secret: koyeb.PtrString("token"), Scopes: []string{"region:fra"}},
+				{Key: koyeb.PtrString("TOKEN"), Secret: koyeb.PtrString("token"), Scopes: []string{"region:was"}},
+			},
+			expected: []dotenv.Variable{
+				{Key: "ENV", Value: "production"},
+				{Key: "TOKEN", Value: "token", Secret: true},
+			},
+		},
+		"different_values": {
+			env: []koyeb.DeploymentEnv{
+				{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("europe"), Scopes: []string{"region:fra"}},
+				{Key: koyeb.PtrString("ENV"), Value: koyeb.PtrString("america"), Scopes: []string{"region:was"}},
+			},
+			expected: []dotenv.Variable{{Key: "ENV", Value: "europe"}},
+			warning:  true,
+		},
+		"value_and_secret": {
+			env: []koyeb.DeploymentEnv{
+				{Key: koyeb.PtrString("TOKEN"), Value: koyeb.PtrString("token"), Scopes: []string{"region:fra"}},
+				{Key: koyeb.PtrString("TOKEN"), Secret: koyeb.PtrString("token"), Scopes: []string{"region:was"}},
+			},
+			expected: []dotenv.Variable{{Key: "TOKEN", Value: "token"}},
+			warning:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+
+			assert.Equal(t, tc.expected, envToDotenvVariables(tc.env))
+			if tc.warning {
+				assert.Len(t, hook.AllEntries(), 1)
+			} else {
+				assert.Empty(t, hook.AllEntries())
+			}
+		})
+	}
+}
+
+func TestParseSkipCache(t *testing.T) {
+	h := NewServiceHandler()
+
+	tests := map[string]struct {
+		args     []string
+		current  bool
+		expected bool
+	}{
+		"default":                {args: nil, current: true, expected: false},
+		"flag":                   {args: []string{"--skip-cache"}, current: false, expected: true},
+		"file":                   {args: []string{"-f", "svc.yaml"}, current: true, expected: true},
+		"file_flag":              {args: []string{"-f", "svc.yaml", "--skip-cache"}, current: false, expected: true},
+		"file_flag_false":        {args: []string{"-f", "svc.yaml", "--skip-cache=false"}, current: true, expected: false},
+		"file_without_skipcache": {args: []string{"-f", "svc.yaml"}, current: false, expected: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+			h.addServiceDefinitionFlags(flags)
+			h.addServiceDefinitionFileFlag(flags)
+			assert.NoError(t, flags.Parse(tc.args))
+			assert.Equal(t, tc.expected, h.parseSkipCache(flags, tc.current))
+		})
+	}
+}
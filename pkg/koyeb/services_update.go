@@ -22,7 +22,8 @@ func (h *ServiceHandler) Update(ctx *CLIContext, cmd *cobra.Command, args []stri
 		return err
 	}
 
-	wait, _ := cmd.Flags().GetBool("wait")
+	// --logs implies --wait
+	wait := !h.quiet && (GetBoolFlags(cmd, "wait") || GetBoolFlags(cmd, "logs"))
 	waitTimeout, _ := cmd.Flags().GetDuration("wait-timeout")
 
 	res, resp, err := ctx.Client.ServicesApi.UpdateService(ctx.Context, service).Service(*updateService).Execute()
@@ -40,6 +41,9 @@ func (h *ServiceHandler) Update(ctx *CLIContext, cmd *cobra.Command, args []stri
 	)
 
 	defer func() {
+		if h.quiet {
+			return
+		}
 		res, _, err := ctx.Client.ServicesApi.GetService(ctx.Context, res.Service.GetId()).Execute()
 		if err != nil {
 			return
@@ -53,6 +57,11 @@ func (h *ServiceHandler) Update(ctx *CLIContext, cmd *cobra.Command, args []stri
 		ctxd, cancel := context.WithTimeout(ctx.Context, waitTimeout)
 		defer cancel()
 
+		if GetBoolFlags(cmd, "logs") {
+			follower := FollowDeploymentLogs(ctx, res.Service.GetLatestDeploymentId(), GetBoolFlags(cmd, "full"))
+			defer follower.Stop()
+		}
+
 		for range ticker(ctxd, 2*time.Second) {
 			res, resp, err := ctx.Client.DeploymentsApi.GetDeployment(ctxd, res.Service.GetLatestDeploymentId()).Execute()
 			if err != nil {
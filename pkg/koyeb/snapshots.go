@@ -17,9 +17,10 @@ func NewSnapshotCmd() *cobra.Command {
 	}
 
 	createSnapshotCmd := &cobra.Command{
-		Use:   "create NAME PARENT_VOLUME",
-		Short: "Create a new snapshot",
-		Args:  cobra.ExactArgs(2),
+		Use:               "create NAME PARENT_VOLUME",
+		Short:             "Create a new snapshot",
+		ValidArgsFunction: completeArgs(nil, completeVolumes),
+		Args:              cobra.ExactArgs(2),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			req := koyeb.NewCreateSnapshotRequestWithDefaults()
 
@@ -37,9 +38,10 @@ func NewSnapshotCmd() *cobra.Command {
 	snapshotCmd.AddCommand(createSnapshotCmd)
 
 	getSnapshotCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get a snapshot",
-		Args:  cobra.ExactArgs(1),
+		Use:               "get NAME",
+		Short:             "Get a snapshot",
+		ValidArgsFunction: completeArgs(completeSnapshots),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			return h.Get(ctx, cmd, args)
 		}),
@@ -56,9 +58,10 @@ func NewSnapshotCmd() *cobra.Command {
 	snapshotCmd.AddCommand(listSnapshotCmd)
 
 	updateSnapshotCmd := &cobra.Command{
-		Use:   "update NAME",
-		Short: "Update a snapshot",
-		Args:  cobra.ExactArgs(1),
+		Use:               "update NAME",
+		Short:             "Update a snapshot",
+		ValidArgsFunction: completeArgs(completeSnapshots),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			req := koyeb.NewUpdateSnapshotRequestWithDefaults()
 
@@ -74,9 +77,10 @@ func NewSnapshotCmd() *cobra.Command {
 	snapshotCmd.AddCommand(updateSnapshotCmd)
 
 	deleteSnapshotCmd := &cobra.Command{
-		Use:   "delete NAME",
-		Short: "Delete a snapshot",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete NAME",
+		Short:             "Delete a snapshot",
+		ValidArgsFunction: completeArgs(completeSnapshots),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			return h.Delete(ctx, cmd, args)
 		}),
@@ -0,0 +1,406 @@
+package koyeb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/archive"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+)
+
+// syncRemoteNotFound is the exit code of the checksum command when the remote
+// directory does not exist.
+const syncRemoteNotFound = 3
+
+type SyncOptions struct {
+	// Delete the files of the destination which do not exist in the source
+	Delete bool
+	// Exclude the files and directories matching these patterns, which use the .gitignore syntax
+	Exclude []string
+	// DryRun only computes the changes, without transferring anything
+	DryRun bool
+	// Keep the owner and the exact permissions of the files
+	PreservePermissions bool
+}
+
+// SyncManager synchronizes the content of the directory Src to the directory
+// Dst, one of them being on an instance. Only the files whose checksum differs
+// are transferred.
+type SyncManager struct {
+	Src     *FileSpec
+	Dst     *FileSpec
+	Options SyncOptions
+
+	client  *ExecAPIClient
+	exclude archive.Options
+}
+
+type syncChange struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	size   int64
+}
+
+// syncFile is a file of the source or destination directory, whose path is
+// relative to the directory.
+type syncFile struct {
+	checksum string
+	size     int64
+}
+
+func NewSyncManager(src, dst *FileSpec, opts SyncOptions) (*SyncManager, error) {
+	if err := ValidateTargets(src, dst); err != nil {
+		return nil, err
+	}
+
+	return &SyncManager{
+		Src:     src,
+		Dst:     dst,
+		Options: opts,
+	}, nil
+}
+
+// Sync computes the changes to apply to Dst and applies them, unless
+// Options.DryRun is set.
+func (manager *SyncManager) Sync(ctx *CLIContext) ([]syncChange, error) {
+	manager.client = ctx.ExecClient
+
+	exclude, err := archive.NewIgnoreMatcher(strings.NewReader(strings.Join(manager.Options.Exclude, "\n")))
+	if err != nil {
+		return nil, &errors.CLIError{
+			What:       "Error while synchronizing",
+			Why:        "unable to parse the --exclude patterns",
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Fix the --exclude patterns and try again",
+		}
+	}
+	manager.exclude = archive.Options{Ignore: exclude}
+
+	execCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srcFiles, err := manager.checksums(execCtx, manager.Src)
+	if err != nil {
+		return nil, err
+	}
+	if srcFiles == nil {
+		return nil, &errors.CLIError{
+			What:       "Error while synchronizing",
+			Why:        fmt.Sprintf("The source directory %s doesn't exist", manager.Src.FilePath),
+			Additional: nil,
+			Orig:       nil,
+			Solution:   "Make sure that the source path is an existing directory",
+		}
+	}
+	dstFiles, err := manager.checksums(execCtx, manager.Dst)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffSyncFiles(srcFiles, dstFiles, manager.Options.Delete)
+	if manager.Options.DryRun {
+		return changes, nil
+	}
+
+	transfer := []syncChange{}
+	deleted := []string{}
+	for _, change := range changes {
+		if change.Change == "deleted" {
+			deleted = append(deleted, change.Path)
+		} else {
+			transfer = append(transfer, change)
+		}
+	}
+
+	if len(transfer) > 0 {
+		if manager.Src.InstanceID == "" {
+			err = manager.push(execCtx, transfer)
+		} else {
+			err = manager.pull(execCtx, transfer)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(deleted) > 0 {
+		if err := manager.delete(execCtx, deleted); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// diffSyncFiles returns the files of src which are added or modified in dst,
+// and if delete is set, the files of dst which do not exist in src.
+func diffSyncFiles(src map[string]syncFile, dst map[string]syncFile, delete bool) []syncChange {
+	ret := []syncChange{}
+	for name, file := range src {
+		existing, ok := dst[name]
+		switch {
+		case !ok:
+			ret = append(ret, syncChange{Path: name, Change: "added", size: file.size})
+		case existing.checksum != file.checksum:
+			ret = append(ret, syncChange{Path: name, Change: "modified", size: file.size})
+		}
+	}
+	if delete {
+		for name := range dst {
+			if _, ok := src[name]; !ok {
+				ret = append(ret, syncChange{Path: name, Change: "deleted"})
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Path < ret[j].Path
+	})
+	return ret
+}
+
+// checksums returns the SHA-256 checksums of the files of the directory, or
+// nil if the directory does not exist.
+func (manager *SyncManager) checksums(ctx context.Context, spec *FileSpec) (map[string]syncFile, error) {
+	if spec.InstanceID == "" {
+		return manager.localChecksums(spec.FilePath)
+	}
+	return manager.remoteChecksums(ctx, spec)
+}
+
+func (manager *SyncManager) localChecksums(dir string) (map[string]syncFile, error) {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err == nil && !info.IsDir() {
+		err = fmt.Errorf("%s is not a directory", dir)
+	}
+	if err != nil {
+		return nil, newSyncError(dir, err)
+	}
+
+	ret := map[string]syncFile{}
+	err = filepath.Walk(dir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		if relativePath != "." && manager.exclude.Excludes(relativePath, fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		ret[relativePath] = syncFile{checksum: hex.EncodeToString(h.Sum(nil)), size: fi.Size()}
+		return nil
+	})
+	if err != nil {
+		return nil, newSyncError(dir, err)
+	}
+	return ret, nil
+}
+
+func (manager *SyncManager) remoteChecksums(ctx context.Context, spec *FileSpec) (map[string]syncFile, error) {
+	var stdout bytes.Buffer
+	retCode, err := execInstance(ctx, manager.client, spec.InstanceID, strings.NewReader(""), &stdout,
+		"sh", "-c", fmt.Sprintf(`[ -d "$1" ] || exit %d; cd -- "$1" && find . -type f -exec sha256sum {} +`, syncRemoteNotFound), "sh", spec.FilePath)
+	if err != nil {
+		return nil, newSyncError(spec.FilePath, err)
+	}
+	if retCode == syncRemoteNotFound {
+		return nil, nil
+	}
+	if retCode != 0 {
+		return nil, &errors.CLIError{
+			What:       "Error while synchronizing",
+			Why:        fmt.Sprintf("Failed computing the checksums of the remote directory %s", spec.FilePath),
+			Additional: []string{"The instance needs the commands find and sha256sum, provided for example by coreutils or busybox"},
+			Orig:       fmt.Errorf("return code: %v", retCode),
+			Solution:   "Make sure that the remote directory is readable and that sha256sum is installed in the instance",
+		}
+	}
+
+	ret := map[string]syncFile{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		// The format is "<checksum>  ./<path>", or "<checksum> *./<path>" in binary mode
+		checksum, name, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+		name = strings.TrimPrefix(name, "./")
+		if manager.exclude.Excludes(name, false) {
+			continue
+		}
+		ret[name] = syncFile{checksum: checksum}
+	}
+	return ret, nil
+}
+
+// push sends the files of the local source directory to the instance.
+func (manager *SyncManager) push(ctx context.Context, changes []syncChange) error {
+	only := map[string]bool{}
+	total := int64(0)
+	for _, change := range changes {
+		only[change.Path] = true
+		total += change.size
+	}
+	opts := tarOptions{
+		preservePermissions: manager.Options.PreservePermissions,
+		exclude:             manager.exclude,
+		progress:            newProgressBar("Synchronizing", total),
+		only:                only,
+	}
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	tarErr := make(chan error, 1)
+	go func() {
+		err := Tar(manager.Src.FilePath, writer, opts)
+		writer.CloseWithError(err)
+		tarErr <- err
+	}()
+
+	extract := `mkdir -p -- "$1" && tar -C "$1" -x -f -`
+	if opts.preservePermissions {
+		extract += " -p"
+	} else {
+		// Do not restore the owner of the local files
+		extract += " -o"
+	}
+	retCode, err := execInstance(ctx, manager.client, manager.Dst.InstanceID, reader, os.Stdout,
+		"sh", "-c", extract, "sh", manager.Dst.FilePath)
+	reader.Close()
+	opts.progress.Finish()
+	if err := <-tarErr; err != nil && !stderrors.Is(err, io.ErrClosedPipe) {
+		return newSyncError(manager.Src.FilePath, err)
+	}
+	if err != nil || retCode != 0 {
+		return newSyncError(manager.Src.FilePath, fmt.Errorf("err: %v, return code: %v", err, retCode))
+	}
+	return nil
+}
+
+// pull retrieves the files of the remote source directory.
+func (manager *SyncManager) pull(ctx context.Context, changes []syncChange) error {
+	if err := os.MkdirAll(manager.Dst.FilePath, 0755); err != nil {
+		return newSyncError(manager.Dst.FilePath, err)
+	}
+
+	names := []string{}
+	for _, change := range changes {
+		names = append(names, change.Path)
+	}
+	opts := tarOptions{
+		preservePermissions: manager.Options.PreservePermissions,
+		progress:            newProgressBar("Synchronizing", 0),
+	}
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	untarErr := make(chan error, 1)
+	go func() {
+		err := Untar(manager.Dst.FilePath, reader, opts)
+		// Unblock the exec if the extraction failed
+		reader.CloseWithError(err)
+		untarErr <- err
+	}()
+
+	// The list of files to retrieve is sent on the standard input of tar
+	retCode, err := execInstance(ctx, manager.client, manager.Src.InstanceID, strings.NewReader(strings.Join(names, "\n")+"\n"), writer,
+		"tar", "-C", manager.Src.FilePath, "-c", "-f", "-", "-T", "-")
+	writer.Close()
+	extractErr := <-untarErr
+	opts.progress.Finish()
+	if err != nil || retCode != 0 || extractErr != nil {
+		return newSyncError(manager.Src.FilePath, fmt.Errorf("err: %v, return code: %v, untar err: %v", err, retCode, extractErr))
+	}
+	return nil
+}
+
+// delete removes the files of the destination directory.
+func (manager *SyncManager) delete(ctx context.Context, names []string) error {
+	if manager.Dst.InstanceID == "" {
+		for _, name := range names {
+			if err := os.Remove(filepath.Join(manager.Dst.FilePath, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+				return newSyncError(manager.Dst.FilePath, err)
+			}
+		}
+		return nil
+	}
+
+	command := append([]string{"sh", "-c", `cd -- "$1" && shift && rm -f -- "$@"`, "sh", manager.Dst.FilePath}, names...)
+	retCode, err := execInstance(ctx, manager.client, manager.Dst.InstanceID, strings.NewReader(""), os.Stdout, command...)
+	if err != nil || retCode != 0 {
+		return newSyncError(manager.Dst.FilePath, fmt.Errorf("unable to delete the files: err: %v, return code: %v", err, retCode))
+	}
+	return nil
+}
+
+func newSyncError(path string, err error) *errors.CLIError {
+	return &errors.CLIError{
+		What:       "Error while synchronizing",
+		Why:        fmt.Sprintf("Failed synchronizing path %v", path),
+		Additional: []string{"This might indicate issues with connectivity to the instance"},
+		Orig:       err,
+		Solution:   "Make sure that your network connection is stable. If the problem persists, try to update the CLI to the latest version.",
+	}
+}
+
+type SyncReply struct {
+	changes []syncChange
+}
+
+func (SyncReply) Title() string {
+	return "Changes"
+}
+
+func (r *SyncReply) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r.changes)
+}
+
+func (r *SyncReply) Headers() []string {
+	return []string{"path", "change"}
+}
+
+func (r *SyncReply) Fields() []map[string]string {
+	resp := []map[string]string{}
+	for _, change := range r.changes {
+		resp = append(resp, map[string]string{
+			"path":   change.Path,
+			"change": change.Change,
+		})
+	}
+	return resp
+}
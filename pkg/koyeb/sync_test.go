@@ -0,0 +1,75 @@
+package koyeb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSyncFiles(t *testing.T) {
+	src := map[string]syncFile{
+		"main.go":        {checksum: "a", size: 10},
+		"static/app.js":  {checksum: "b", size: 20},
+		"static/new.css": {checksum: "c", size: 30},
+	}
+
+	tests := map[string]struct {
+		src      map[string]syncFile
+		dst      map[string]syncFile
+		delete   bool
+		expected []syncChange
+	}{
+		"empty_destination": {
+			src: src,
+			dst: nil,
+			expected: []syncChange{
+				{Path: "main.go", Change: "added", size: 10},
+				{Path: "static/app.js", Change: "added", size: 20},
+				{Path: "static/new.css", Change: "added", size: 30},
+			},
+		},
+		"up_to_date": {
+			src:      src,
+			dst:      src,
+			delete:   true,
+			expected: []syncChange{},
+		},
+		"added_and_modified": {
+			src: src,
+			dst: map[string]syncFile{
+				"main.go":       {checksum: "a", size: 10},
+				"static/app.js": {checksum: "old", size: 5},
+				"old.txt":       {checksum: "d", size: 1},
+			},
+			expected: []syncChange{
+				{Path: "static/app.js", Change: "modified", size: 20},
+				{Path: "static/new.css", Change: "added", size: 30},
+			},
+		},
+		"delete": {
+			src: src,
+			dst: map[string]syncFile{
+				"main.go":       {checksum: "a", size: 10},
+				"static/app.js": {checksum: "b", size: 20},
+				"old.txt":       {checksum: "d", size: 1},
+			},
+			delete: true,
+			expected: []syncChange{
+				{Path: "old.txt", Change: "deleted"},
+				{Path: "static/new.css", Change: "added", size: 30},
+			},
+		},
+		"empty_source": {
+			src:      map[string]syncFile{},
+			dst:      map[string]syncFile{"main.go": {checksum: "a", size: 10}},
+			delete:   true,
+			expected: []syncChange{{Path: "main.go", Change: "deleted"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, diffSyncFiles(tc.src, tc.dst, tc.delete))
+		})
+	}
+}
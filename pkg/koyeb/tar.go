@@ -8,25 +8,71 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/archive"
 )
 
+// tarOptions configures Tar and Untar.
+type tarOptions struct {
+	// compress the stream with gzip
+	compress bool
+	// preservePermissions restores the owner and the exact permissions of the
+	// files. Otherwise, the permissions are masked by the umask when the files
+	// are created, and the owner is the current user.
+	preservePermissions bool
+	// exclude the files and directories matching the --exclude patterns
+	exclude archive.Options
+	// progress records the bytes of the files transferred
+	progress *progressBar
+	// only restricts Tar to these files, whose paths are relative to src and
+	// use / as separator. If nil, all the files are written.
+	only map[string]bool
+}
+
+// fileMode converts the unix permissions of a tar header, including the
+// setuid, setgid and sticky bits, to a FileMode.
+func fileMode(mode int64) os.FileMode {
+	ret := os.FileMode(mode & 0777)
+	if mode&04000 != 0 {
+		ret |= os.ModeSetuid
+	}
+	if mode&02000 != 0 {
+		ret |= os.ModeSetgid
+	}
+	if mode&01000 != 0 {
+		ret |= os.ModeSticky
+	}
+	return ret
+}
+
+// restoreOwnerAndMode sets the exact permissions of target, and its owner when
+// the CLI runs as root.
+func restoreOwnerAndMode(target string, uid int, gid int, mode int64) error {
+	if os.Geteuid() == 0 {
+		if err := os.Lchown(target, uid, gid); err != nil {
+			return err
+		}
+	}
+	return os.Chmod(target, fileMode(mode))
+}
+
 // Slightly modified version of https://gist.github.com/sdomino/e6bc0c98f87843bc26bb#file-targz-go
 
-// Tar takes a source and variable writers and walks 'source' writing each file
-// found to the tar writer; the purpose for accepting multiple writers is to allow
-// for multiple outputs (for example a file, or md5 hash)
-func Tar(src string, writers ...io.Writer) error {
+// Tar walks 'src' and writes each regular file found to w. If src is a
+// directory, the paths in the archive are relative to src.
+func Tar(src string, w io.Writer, opts tarOptions) error {
 	// ensure the src actually exists before trying to tar it
 	if _, err := os.Stat(src); err != nil {
 		return fmt.Errorf("unable to tar files - %v", err.Error())
 	}
 
-	mw := io.MultiWriter(writers...)
-
-	gzw := gzip.NewWriter(mw)
-	defer gzw.Close()
+	if opts.compress {
+		gzw := gzip.NewWriter(w)
+		defer gzw.Close()
+		w = gzw
+	}
 
-	tw := tar.NewWriter(mw)
+	tw := tar.NewWriter(w)
 	defer tw.Close()
 
 	// walk path
@@ -37,11 +83,26 @@ func Tar(src string, writers ...io.Writer) error {
 			return err
 		}
 
+		relativePath, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		if relativePath != "." && opts.exclude.Excludes(filepath.ToSlash(relativePath), fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// return on non-regular files (thanks to [kumo](https://medium.com/@komuw/just-like-you-did-fbdd7df829d3) for this suggested update)
 		if !fi.Mode().IsRegular() {
 			return nil
 		}
 
+		if opts.only != nil && !opts.only[filepath.ToSlash(relativePath)] {
+			return nil
+		}
+
 		// create a new dir/file header
 		header, err := tar.FileInfoHeader(fi, fi.Name())
 		if err != nil {
@@ -49,12 +110,11 @@ func Tar(src string, writers ...io.Writer) error {
 		}
 
 		// update the name to correctly reflect the desired destination when untaring
-		header.Name = strings.TrimPrefix(strings.ReplaceAll(file, src, ""), string(filepath.Separator))
+		header.Name = filepath.ToSlash(relativePath)
 		// this happens when we tar a single file
-		if header.Name == "" {
+		if relativePath == "." {
 			header.Name = filepath.Base(file)
 		}
-
 		// write the header
 		if err := tw.WriteHeader(header); err != nil {
 			return err
@@ -67,7 +127,8 @@ func Tar(src string, writers ...io.Writer) error {
 		}
 
 		// copy file data into tar writer
-		if _, err := io.Copy(tw, f); err != nil {
+		if _, err := io.Copy(tw, opts.progress.Reader(f)); err != nil {
+			f.Close()
 			return err
 		}
 
@@ -80,23 +141,35 @@ func Tar(src string, writers ...io.Writer) error {
 }
 
 // https://gist.github.com/sdomino/635a5ed4f32c93aad131#file-untargz-go
-func Untar(dst string, r io.Reader) error {
-	gzr, err := gzip.NewReader(r)
-	if err != nil {
-		return err
+
+// Untar extracts the regular files, directories and symbolic links read from r
+// to dst. The symbolic links are created last, so the files of the archive are
+// never written through them.
+func Untar(dst string, r io.Reader, opts tarOptions) error {
+	if opts.compress {
+		gzr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gzr.Close()
+		r = gzr
 	}
-	defer gzr.Close()
 
-	tr := tar.NewReader(gzr)
+	tr := tar.NewReader(r)
+	root := filepath.Clean(dst)
+	// delayed are the directories and symbolic links, which are updated once
+	// the files are extracted
+	delayed := []*tar.Header{}
 
 	for {
 		header, err := tr.Next()
 
 		switch {
 
-		// if no more files are found return
+		// if no more files are found, create the symbolic links and set the
+		// permissions of the directories
 		case err == io.EOF:
-			return nil
+			return untarDelayed(dst, delayed, opts)
 
 		// return any other error
 		case err != nil:
@@ -109,10 +182,9 @@ func Untar(dst string, r io.Reader) error {
 
 		// the target location where the dir/file should be created
 		target := filepath.Join(dst, header.Name)
-
-		// the following switch could also be done using fi.Mode(), not sure if there
-		// a benefit of using one vs. the other.
-		// fi := header.FileInfo()
+		if target != root && !strings.HasPrefix(target, root+string(filepath.Separator)) {
+			return fmt.Errorf("the archive contains the path %s which is outside of the destination directory", header.Name)
+		}
 
 		// check the file type
 		switch header.Typeflag {
@@ -120,26 +192,81 @@ func Untar(dst string, r io.Reader) error {
 		// if its a dir and it doesn't exist create it
 		case tar.TypeDir:
 			if _, err := os.Stat(target); err != nil {
-				if err := os.MkdirAll(target, 0755); err != nil {
+				if err := os.MkdirAll(target, fileMode(header.Mode)&os.ModePerm); err != nil {
 					return err
 				}
 			}
+			delayed = append(delayed, header)
+
+		case tar.TypeSymlink:
+			delayed = append(delayed, header)
 
 		// if it's a file create it
 		case tar.TypeReg:
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
+			// parent directories are not in the archive when files are listed explicitly
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+
+			// the umask applies to the mode when the file is created
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fileMode(header.Mode)&os.ModePerm)
 			if err != nil {
 				return err
 			}
 
 			// copy over contents
-			if _, err := io.Copy(f, tr); err != nil {
+			if _, err := io.Copy(f, opts.progress.Reader(tr)); err != nil {
+				f.Close()
 				return err
 			}
 
 			// manually close here after each file operation; defering would cause each file close
 			// to wait until all operations have completed.
 			f.Close()
+
+			if opts.preservePermissions {
+				if err := restoreOwnerAndMode(target, header.Uid, header.Gid, header.Mode); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// untarDelayed creates the symbolic links, and restores the owner and the
+// permissions of the directories, in the reverse order so the permissions of a
+// directory do not prevent updating its content.
+func untarDelayed(dst string, delayed []*tar.Header, opts tarOptions) error {
+	for i := len(delayed) - 1; i >= 0; i-- {
+		header := delayed[i]
+		target := filepath.Join(dst, header.Name)
+
+		if header.Typeflag == tar.TypeSymlink {
+			// replace the previous copy of the link
+			if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				if err := os.Remove(target); err != nil {
+					return err
+				}
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+			if opts.preservePermissions && os.Geteuid() == 0 {
+				if err := os.Lchown(target, header.Uid, header.Gid); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if opts.preservePermissions {
+			if err := restoreOwnerAndMode(target, header.Uid, header.Gid, header.Mode); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
@@ -0,0 +1,81 @@
+package koyeb
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUntar(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links and unix permissions are not supported on windows")
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	entries := []*tar.Header{
+		{Name: "empty", Typeflag: tar.TypeDir, Mode: 0700},
+		{Name: "bin/run.sh", Typeflag: tar.TypeReg, Mode: 0750, Size: 2},
+		{Name: "bin/link", Typeflag: tar.TypeSymlink, Linkname: "run.sh"},
+		{Name: "escape", Typeflag: tar.TypeSymlink, Linkname: "/tmp"},
+	}
+	for _, header := range entries {
+		require.NoError(t, tw.WriteHeader(header))
+		if header.Size > 0 {
+			_, err := tw.Write([]byte("ok"))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+
+	dst := t.TempDir()
+	require.NoError(t, Untar(dst, &buf, tarOptions{}))
+
+	info, err := os.Stat(filepath.Join(dst, "empty"))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	// The mode of the archive is kept, and masked by the umask
+	info, err = os.Stat(filepath.Join(dst, "bin/run.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750)&info.Mode().Perm(), info.Mode().Perm())
+	assert.NotZero(t, info.Mode().Perm()&0100)
+
+	link, err := os.Readlink(filepath.Join(dst, "bin/link"))
+	require.NoError(t, err)
+	assert.Equal(t, "run.sh", link)
+
+	link, err = os.Readlink(filepath.Join(dst, "escape"))
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp", link)
+}
+
+func TestUntarPreservePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions are not supported on windows")
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "shared", Typeflag: tar.TypeDir, Mode: 01777, Uid: os.Getuid(), Gid: os.Getgid()}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "shared/file", Typeflag: tar.TypeReg, Mode: 0777, Uid: os.Getuid(), Gid: os.Getgid()}))
+	require.NoError(t, tw.Close())
+
+	dst := t.TempDir()
+	require.NoError(t, Untar(dst, &buf, tarOptions{preservePermissions: true}))
+
+	// The exact permissions are restored, regardless of the umask
+	info, err := os.Stat(filepath.Join(dst, "shared"))
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeDir|os.ModeSticky|0777, info.Mode())
+
+	info, err = os.Stat(filepath.Join(dst, "shared/file"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0777), info.Mode())
+}
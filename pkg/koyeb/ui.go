@@ -0,0 +1,662 @@
+package koyeb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/renderer"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// uiRefreshInterval is the delay between two reloads of the current list.
+	uiRefreshInterval = 5 * time.Second
+	// uiLogsHistory is the duration of the logs displayed when opening the logs.
+	uiLogsHistory = 15 * time.Minute
+	// uiLogsMaxLines is the number of log lines kept in memory.
+	uiLogsMaxLines = 5000
+)
+
+func NewUICmd() *cobra.Command {
+	h := NewUIHandler()
+
+	uiCmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Browse your resources in an interactive terminal UI",
+		Long: `Browse your organizations, apps, services, deployments and instances in an interactive terminal UI.
+
+The lists are refreshed every 5 seconds to display the live status of the resources.
+
+Keys:
+  ↑/↓, j/k, PgUp/PgDn    Move the selection
+  Enter                  Open the selection: the services of an app, the instances of a service or a deployment, the logs of an instance
+  Esc, Backspace         Go back
+  o                      List the organizations, and switch to the selected one with Enter
+  d                      List the deployments of the selected service
+  l                      Tail the logs of the selected service, deployment or instance
+  e                      Run a shell in the selected instance
+  R                      Redeploy the selected service
+  p, u                   Pause or resume the selected app or service
+  r                      Refresh the list
+  q, Ctrl+C              Quit
+
+Redeploy, pause and resume ask for a confirmation.`,
+		Args: cobra.NoArgs,
+		RunE: WithCLIContext(h.Run),
+	}
+	uiCmd.Flags().String("shell", "/bin/sh", "Shell to run in the instances with the key e")
+	return uiCmd
+}
+
+func NewUIHandler() *UIHandler {
+	return &UIHandler{
+		appHandler:     NewAppHandler(),
+		serviceHandler: NewServiceHandler(),
+	}
+}
+
+type UIHandler struct {
+	appHandler     *AppHandler
+	serviceHandler *ServiceHandler
+}
+
+func (h *UIHandler) Run(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	t, err := newUITerminal()
+	if err != nil {
+		return &errors.CLIError{
+			What:       "Error while starting the UI",
+			Why:        "unable to set up the terminal",
+			Additional: nil,
+			Orig:       err,
+			Solution:   "Run koyeb ui in an interactive terminal",
+		}
+	}
+	defer t.Close()
+
+	// Display the warnings and the errors in the status line instead of
+	// writing them over the UI
+	hook := &uiLogHook{messages: make(chan string, 16)}
+	hooks := log.StandardLogger().ReplaceHooks(log.LevelHooks{})
+	log.AddHook(hook)
+	log.SetOutput(io.Discard)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.StandardLogger().ReplaceHooks(hooks)
+	}()
+
+	ui := &uiState{
+		h:       h,
+		ctx:     ctx,
+		cmd:     cmd,
+		term:    t,
+		views:   []*uiView{newUIAppsView()},
+		loaded:  make(chan uiLoadResult, 16),
+		shell:   GetStringFlags(cmd, "shell"),
+		message: "Press q to quit",
+	}
+	ui.load(ui.view())
+
+	refresh := time.NewTicker(uiRefreshInterval)
+	defer refresh.Stop()
+	resizes := watchTermSize(ctx.Context, os.Stdout)
+
+	for {
+		ui.draw()
+
+		var logs <-chan WatchLogsEntry
+		if ui.logs != nil {
+			logs = ui.logs.entries
+		}
+
+		select {
+		case <-ctx.Context.Done():
+			return nil
+		case buf, ok := <-t.input:
+			if !ok {
+				return nil
+			}
+			for _, key := range decodeKeys(buf) {
+				if quit := ui.handleKey(key); quit {
+					ui.closeLogs()
+					return nil
+				}
+			}
+		case res := <-ui.loaded:
+			res.view.loading = false
+			res.view.err = res.err
+			if res.err == nil {
+				res.view.setRows(res.rows)
+				res.view.loadedAt = time.Now()
+			}
+		case entry, ok := <-logs:
+			ui.addLogEntry(entry, ok)
+		case msg := <-hook.messages:
+			ui.message = msg
+		case <-refresh.C:
+			if ui.logs == nil {
+				ui.load(ui.view())
+			}
+		case <-resizes:
+		}
+	}
+}
+
+// uiLogHook sends the warnings and the errors logged while the UI is running
+// to the status line.
+type uiLogHook struct {
+	messages chan string
+}
+
+func (hook *uiLogHook) Levels() []log.Level {
+	return []log.Level{log.PanicLevel, log.FatalLevel, log.ErrorLevel, log.WarnLevel}
+}
+
+func (hook *uiLogHook) Fire(entry *log.Entry) error {
+	select {
+	case hook.messages <- entry.Message:
+	default:
+	}
+	return nil
+}
+
+type uiLoadResult struct {
+	view *uiView
+	rows []uiRow
+	err  error
+}
+
+// uiConfirmation is an action which runs when the user answers y.
+type uiConfirmation struct {
+	prompt string
+	action func() (string, error)
+}
+
+// uiLogs is the screen displaying the logs.
+type uiLogs struct {
+	title   string
+	lines   []string
+	entries <-chan WatchLogsEntry
+	cancel  context.CancelFunc
+	// scroll is the number of lines hidden at the bottom. When 0, the new
+	// lines are displayed as they arrive
+	scroll int
+}
+
+// uiState is the state of koyeb ui: a stack of lists, and optionally the logs
+// displayed over them.
+type uiState struct {
+	h       *UIHandler
+	ctx     *CLIContext
+	cmd     *cobra.Command
+	term    *uiTerminal
+	views   []*uiView
+	loaded  chan uiLoadResult
+	shell   string
+	message string
+	confirm *uiConfirmation
+	logs    *uiLogs
+}
+
+func (ui *uiState) view() *uiView {
+	return ui.views[len(ui.views)-1]
+}
+
+// load reloads the rows of v in the background.
+func (ui *uiState) load(v *uiView) {
+	if v.loading {
+		return
+	}
+	v.loading = true
+	ctx := ui.ctx
+	go func() {
+		// A panic would leave the terminal in raw mode: report it as an error
+		defer func() {
+			if r := recover(); r != nil {
+				ui.loaded <- uiLoadResult{view: v, err: fmt.Errorf("unexpected error: %v", r)}
+			}
+		}()
+		rows, err := v.load(ctx)
+		ui.loaded <- uiLoadResult{view: v, rows: rows, err: err}
+	}()
+}
+
+func (ui *uiState) push(v *uiView) {
+	ui.views = append(ui.views, v)
+	ui.load(v)
+}
+
+// handleKey handles a key pressed by the user, and returns true to quit.
+func (ui *uiState) handleKey(key uiKey) bool {
+	if ui.confirm != nil {
+		confirm := ui.confirm
+		ui.confirm = nil
+		if key != "y" && key != "Y" {
+			ui.message = "Cancelled"
+			return false
+		}
+		ui.message = confirm.prompt + " y"
+		ui.draw()
+		msg, err := confirm.action()
+		if err != nil {
+			ui.message = uiErrorMessage(err)
+		} else {
+			ui.message = msg
+		}
+		ui.load(ui.view())
+		return false
+	}
+
+	if key == "q" || key == uiKeyCtrlC {
+		return true
+	}
+	if ui.logs != nil {
+		ui.handleLogsKey(key)
+		return false
+	}
+
+	v := ui.view()
+	row := v.current()
+	switch key {
+	case uiKeyUp, "k":
+		v.selected--
+	case uiKeyDown, "j":
+		v.selected++
+	case uiKeyPageUp:
+		v.selected -= ui.listHeight()
+	case uiKeyPageDown:
+		v.selected += ui.listHeight()
+	case uiKeyHome, "g":
+		v.selected = 0
+	case uiKeyEnd, "G":
+		v.selected = len(v.rows) - 1
+	case uiKeyEscape, uiKeyBackspace, "h":
+		if len(ui.views) > 1 {
+			ui.views = ui.views[:len(ui.views)-1]
+			ui.load(ui.view())
+		}
+	case "r":
+		ui.load(v)
+	case "o":
+		if v.resource != uiOrganizations {
+			ui.push(newUIOrganizationsView())
+		}
+	case uiKeyEnter:
+		if row != nil {
+			ui.open(v.resource, *row)
+		}
+	case "d":
+		if row != nil && v.resource == uiServices {
+			ui.push(newUIDeploymentsView(*row))
+		}
+	case "l":
+		if row != nil && v.resource != uiOrganizations && v.resource != uiApps {
+			ui.openLogs(v.resource, *row)
+		}
+	case "e":
+		if row != nil && v.resource == uiInstances {
+			ui.exec(*row)
+		}
+	case "R":
+		if row != nil && v.resource == uiServices {
+			ui.askAction(fmt.Sprintf("Redeploy the service %s?", row.name), []string{"service", "redeploy"}, ui.h.serviceHandler.ReDeploy, *row,
+				fmt.Sprintf("Redeploying the service %s", row.name))
+		}
+	case "p":
+		switch {
+		case row != nil && v.resource == uiApps:
+			ui.askAction(fmt.Sprintf("Pause the app %s?", row.name), []string{"app", "pause"}, ui.h.appHandler.Pause, *row,
+				fmt.Sprintf("Pausing the app %s", row.name))
+		case row != nil && v.resource == uiServices:
+			ui.askAction(fmt.Sprintf("Pause the service %s?", row.name), []string{"service", "pause"}, ui.h.serviceHandler.Pause, *row,
+				fmt.Sprintf("Pausing the service %s", row.name))
+		}
+	case "u":
+		switch {
+		case row != nil && v.resource == uiApps:
+			ui.askAction(fmt.Sprintf("Resume the app %s?", row.name), []string{"app", "resume"}, ui.h.appHandler.Resume, *row,
+				fmt.Sprintf("Resuming the app %s", row.name))
+		case row != nil && v.resource == uiServices:
+			ui.askAction(fmt.Sprintf("Resume the service %s?", row.name), []string{"service", "resume"}, ui.h.serviceHandler.Resume, *row,
+				fmt.Sprintf("Resuming the service %s", row.name))
+		}
+	}
+	v.selected = max(0, min(v.selected, len(v.rows)-1))
+	return false
+}
+
+func (ui *uiState) handleLogsKey(key uiKey) {
+	switch key {
+	case uiKeyEscape, uiKeyBackspace, "h":
+		ui.closeLogs()
+	case uiKeyUp, "k":
+		ui.logs.scroll++
+	case uiKeyDown, "j":
+		ui.logs.scroll--
+	case uiKeyPageUp:
+		ui.logs.scroll += ui.listHeight()
+	case uiKeyPageDown:
+		ui.logs.scroll -= ui.listHeight()
+	case uiKeyHome, "g":
+		ui.logs.scroll = len(ui.logs.lines)
+	case uiKeyEnd, "G":
+		ui.logs.scroll = 0
+	}
+	ui.logs.scroll = max(0, min(ui.logs.scroll, len(ui.logs.lines)-ui.listHeight()))
+}
+
+// open displays the content of the selected row.
+func (ui *uiState) open(resource uiResource, row uiRow) {
+	switch resource {
+	case uiOrganizations:
+		if err := SetupCLIContext(ui.cmd, row.id); err != nil {
+			ui.message = uiErrorMessage(err)
+			return
+		}
+		ui.ctx = GetCLIContext(ui.cmd.Context())
+		ui.views = nil
+		ui.push(newUIAppsView())
+		ui.message = fmt.Sprintf("Switched to the organization %s", row.name)
+	case uiApps:
+		ui.push(newUIServicesView(row))
+	case uiServices, uiDeployments:
+		ui.push(newUIInstancesView(row, resource))
+	case uiInstances:
+		ui.openLogs(resource, row)
+	}
+}
+
+// askAction asks for a confirmation, then runs the handler of the command
+// path, like koyeb service redeploy, with the default values of its flags.
+func (ui *uiState) askAction(prompt string, path []string, handler func(*CLIContext, *cobra.Command, []string) error, row uiRow, done string) {
+	ui.confirm = &uiConfirmation{
+		prompt: prompt + " (y/N)",
+		action: func() (string, error) {
+			cmd, _, err := ui.cmd.Root().Find(path)
+			if err != nil {
+				return "", err
+			}
+			if err := handler(ui.ctx, cmd, []string{row.id}); err != nil {
+				return "", err
+			}
+			return done, nil
+		},
+	}
+}
+
+func (ui *uiState) openLogs(resource uiResource, row uiRow) {
+	var serviceID, deploymentID, instanceID string
+	switch resource {
+	case uiServices:
+		serviceID = row.id
+	case uiDeployments:
+		deploymentID = row.id
+	case uiInstances:
+		instanceID = row.id
+	}
+
+	query, err := ui.ctx.LogsClient.NewWatchLogsQuery("", serviceID, deploymentID, instanceID, time.Now().Add(-uiLogsHistory), "", "", false)
+	if err != nil {
+		ui.message = uiErrorMessage(err)
+		return
+	}
+	ctx, cancel := context.WithCancel(ui.ctx.Context)
+	entries, err := query.Execute(ctx)
+	if err != nil {
+		cancel()
+		ui.message = uiErrorMessage(err)
+		return
+	}
+	ui.logs = &uiLogs{
+		title:   fmt.Sprintf("Logs of %s", row.name),
+		entries: entries,
+		cancel:  cancel,
+	}
+}
+
+func (ui *uiState) closeLogs() {
+	if ui.logs == nil {
+		return
+	}
+	ui.logs.cancel()
+	if ui.logs.entries != nil {
+		// Unblock the goroutine sending the entries until it stops
+		go func(entries <-chan WatchLogsEntry) {
+			for range entries {
+			}
+		}(ui.logs.entries)
+	}
+	ui.logs = nil
+	ui.load(ui.view())
+}
+
+// uiControlSequences matches the escape sequences and the control characters
+// of the logs, which would break the layout of the screen.
+var uiControlSequences = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|[\x00-\x08\x0b-\x1f\x7f]`)
+
+func (ui *uiState) addLogEntry(entry WatchLogsEntry, ok bool) {
+	if !ok {
+		ui.logs.entries = nil
+		ui.message = "The logs stream ended"
+		return
+	}
+	if entry.Err != nil {
+		ui.message = uiErrorMessage(entry.Err)
+		return
+	}
+	msg := strings.ReplaceAll(entry.Msg, "\t", "    ")
+	for _, line := range strings.Split(uiControlSequences.ReplaceAllString(msg, ""), "\n") {
+		ui.logs.lines = append(ui.logs.lines, fmt.Sprintf("%s %s %s",
+			entry.Date.Local().Format("2006-01-02 15:04:05"),
+			uiCell(renderer.FormatID(entry.Labels.InstanceID, false), 8),
+			line,
+		))
+		if ui.logs.scroll > 0 {
+			ui.logs.scroll++
+		}
+	}
+	if extra := len(ui.logs.lines) - uiLogsMaxLines; extra > 0 {
+		ui.logs.lines = ui.logs.lines[extra:]
+	}
+}
+
+// exec suspends the UI and runs a shell in the instance.
+func (ui *uiState) exec(row uiRow) {
+	ui.term.suspend()
+	fmt.Fprintf(os.Stdout, "Running %s in the instance %s. Exit the shell to go back to koyeb ui.\r\n", ui.shell, row.name)
+
+	stdin := ui.term.stdin()
+	code, err := ui.ctx.ExecClient.ExecWithStreams(
+		ui.ctx.Context,
+		&StdStreams{Stdin: stdin, Stdout: os.Stdout, Stderr: os.Stderr},
+		ExecId{Id: row.id, Type: koyeb.EXECCOMMANDREQUESTIDTYPE_INSTANCE_ID},
+		[]string{ui.shell},
+	)
+	stdin.Close()
+	ui.term.resume()
+
+	switch {
+	case err != nil:
+		ui.message = uiErrorMessage(err)
+	case code < 0:
+		ui.message = fmt.Sprintf("The connection to the instance %s was closed", row.name)
+	default:
+		ui.message = fmt.Sprintf("%s exited with code %d", ui.shell, code)
+	}
+}
+
+// listHeight is the number of lines available for the rows or the logs.
+func (ui *uiState) listHeight() int {
+	_, height := ui.term.size()
+	return max(1, height-4)
+}
+
+// draw renders the screen: the title, the list or the logs, the status line
+// and the keys.
+func (ui *uiState) draw() {
+	width, height := ui.term.size()
+	lines := []string{}
+
+	titles := []string{}
+	for _, v := range ui.views {
+		titles = append(titles, v.title)
+	}
+	if ui.logs != nil {
+		titles = append(titles, ui.logs.title)
+	}
+	lines = append(lines, ansiBold+uiCell("koyeb ui › "+strings.Join(titles, " › "), width))
+
+	if ui.logs != nil {
+		lines = append(lines, ui.drawLogs(width)...)
+	} else {
+		lines = append(lines, ui.drawList(width)...)
+	}
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+
+	if ui.confirm != nil {
+		lines = append(lines, ansiYellow+ansiBold+uiCell(ui.confirm.prompt, width))
+	} else {
+		lines = append(lines, uiCell(ui.message, width))
+	}
+	lines = append(lines, ansiGray+uiCell(ui.keysHelp(), width))
+	ui.term.draw(lines)
+}
+
+func (ui *uiState) drawList(width int) []string {
+	v := ui.view()
+	height := ui.listHeight()
+
+	// The error of the last reload replaces the headers, and the previous
+	// rows are kept
+	status := ""
+	switch {
+	case v.err != nil:
+		status = ansiRed + uiCell(uiErrorMessage(v.err), width)
+	case v.loadedAt.IsZero():
+		status = uiCell("Loading...", width)
+	case len(v.rows) == 0:
+		status = uiCell(fmt.Sprintf("No %s", v.resource), width)
+	}
+	if len(v.rows) == 0 {
+		return []string{status}
+	}
+
+	// Column widths: the widest value, up to 40 characters, and the rest of
+	// the line for the last column
+	widths := make([]int, len(v.headers))
+	for i, h := range v.headers {
+		widths[i] = len(h)
+		for _, row := range v.rows {
+			widths[i] = max(widths[i], len([]rune(row.cells[i])))
+		}
+		widths[i] = min(widths[i], 40)
+	}
+
+	formatRow := func(cells []string, colors bool, statusColor string) string {
+		var b strings.Builder
+		remaining := width
+		for i, cell := range cells {
+			w := widths[i]
+			if i == len(cells)-1 || w > remaining {
+				w = remaining
+			}
+			text := uiCell(cell, w)
+			if colors && i == v.statusColumn {
+				text = statusColor + text + ansiReset
+			}
+			b.WriteString(text)
+			remaining -= w
+			if remaining < 2 {
+				break
+			}
+			b.WriteString("  ")
+			remaining -= 2
+		}
+		return b.String()
+	}
+
+	upper := []string{}
+	for _, h := range v.headers {
+		upper = append(upper, strings.ToUpper(h))
+	}
+	lines := []string{ansiBold + formatRow(upper, false, "")}
+	if status != "" {
+		lines[0] = status
+	}
+
+	if v.selected < v.offset {
+		v.offset = v.selected
+	}
+	if v.selected >= v.offset+height {
+		v.offset = v.selected - height + 1
+	}
+	v.offset = max(0, min(v.offset, len(v.rows)-height))
+	for i := v.offset; i < len(v.rows) && i < v.offset+height; i++ {
+		row := v.rows[i]
+		if i == v.selected {
+			lines = append(lines, ansiReverse+formatRow(row.cells, false, ""))
+		} else {
+			lines = append(lines, formatRow(row.cells, true, uiStatusColor(row.status)))
+		}
+	}
+	return lines
+}
+
+func (ui *uiState) drawLogs(width int) []string {
+	height := ui.listHeight() + 1
+	end := len(ui.logs.lines) - ui.logs.scroll
+	start := max(0, end-height)
+	lines := []string{}
+	for _, line := range ui.logs.lines[start:end] {
+		lines = append(lines, uiCell(line, width))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "Waiting for logs...")
+	}
+	return lines
+}
+
+func (ui *uiState) keysHelp() string {
+	if ui.logs != nil {
+		return "↑/↓ scroll  End follow  Esc back  q quit"
+	}
+	keys := []string{"↑/↓ move"}
+	switch ui.view().resource {
+	case uiOrganizations:
+		keys = append(keys, "Enter switch")
+	case uiApps:
+		keys = append(keys, "Enter services", "p pause", "u resume")
+	case uiServices:
+		keys = append(keys, "Enter instances", "d deployments", "l logs", "R redeploy", "p pause", "u resume")
+	case uiDeployments:
+		keys = append(keys, "Enter instances", "l logs")
+	case uiInstances:
+		keys = append(keys, "Enter/l logs", "e shell")
+	}
+	keys = append(keys, "o organizations", "r refresh", "Esc back", "q quit")
+	return strings.Join(keys, "  ")
+}
+
+// uiErrorMessage returns a one line message for an error.
+func uiErrorMessage(err error) string {
+	if cliErr, ok := err.(*errors.CLIError); ok {
+		msg := cliErr.What
+		if cliErr.Why != "" {
+			msg += ": " + cliErr.Why
+		}
+		if cliErr.Orig != nil {
+			msg += fmt.Sprintf(" (%s)", cliErr.Orig)
+		}
+		return msg
+	}
+	return err.Error()
+}
@@ -0,0 +1,240 @@
+package koyeb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences used by koyeb ui.
+const (
+	ansiAltScreenOn  = "\x1b[?1049h"
+	ansiAltScreenOff = "\x1b[?1049l"
+	ansiCursorHide   = "\x1b[?25l"
+	ansiCursorShow   = "\x1b[?25h"
+	ansiCursorHome   = "\x1b[H"
+	ansiClearLine    = "\x1b[K"
+	ansiClearBelow   = "\x1b[J"
+	ansiReset        = "\x1b[0m"
+	ansiBold         = "\x1b[1m"
+	ansiReverse      = "\x1b[7m"
+	ansiRed          = "\x1b[31m"
+	ansiGreen        = "\x1b[32m"
+	ansiYellow       = "\x1b[33m"
+	ansiGray         = "\x1b[90m"
+)
+
+// uiKey is a key pressed by the user: either one of the constants below, or
+// the character typed.
+type uiKey string
+
+const (
+	uiKeyUp        uiKey = "<up>"
+	uiKeyDown      uiKey = "<down>"
+	uiKeyPageUp    uiKey = "<pgup>"
+	uiKeyPageDown  uiKey = "<pgdown>"
+	uiKeyHome      uiKey = "<home>"
+	uiKeyEnd       uiKey = "<end>"
+	uiKeyEnter     uiKey = "<enter>"
+	uiKeyEscape    uiKey = "<esc>"
+	uiKeyBackspace uiKey = "<backspace>"
+	uiKeyCtrlC     uiKey = "<ctrl-c>"
+)
+
+// uiCSIKeys maps the final bytes of the escape sequences sent by terminals to
+// the keys.
+var uiCSIKeys = map[string]uiKey{
+	"A":  uiKeyUp,
+	"B":  uiKeyDown,
+	"H":  uiKeyHome,
+	"F":  uiKeyEnd,
+	"1~": uiKeyHome,
+	"4~": uiKeyEnd,
+	"5~": uiKeyPageUp,
+	"6~": uiKeyPageDown,
+}
+
+// decodeKeys returns the keys of the input read from the terminal. Unknown
+// escape sequences are ignored.
+func decodeKeys(buf []byte) []uiKey {
+	keys := []uiKey{}
+	for len(buf) > 0 {
+		switch c := buf[0]; {
+		case c == 0x1b && len(buf) > 1 && (buf[1] == '[' || buf[1] == 'O'):
+			// CSI (ESC [) or SS3 (ESC O) sequence, terminated by a byte in the range 0x40-0x7e
+			end := 2
+			for end < len(buf) && (buf[end] < 0x40 || buf[end] > 0x7e) {
+				end++
+			}
+			if end == len(buf) {
+				return keys
+			}
+			if key, ok := uiCSIKeys[string(buf[2:end+1])]; ok {
+				keys = append(keys, key)
+			}
+			buf = buf[end+1:]
+		case c == 0x1b:
+			keys = append(keys, uiKeyEscape)
+			buf = buf[1:]
+		case c == '\r' || c == '\n':
+			keys = append(keys, uiKeyEnter)
+			buf = buf[1:]
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, uiKeyBackspace)
+			buf = buf[1:]
+		case c == 0x03:
+			keys = append(keys, uiKeyCtrlC)
+			buf = buf[1:]
+		default:
+			r, size := utf8.DecodeRune(buf)
+			if r != utf8.RuneError && r >= 0x20 {
+				keys = append(keys, uiKey(string(r)))
+			}
+			buf = buf[size:]
+		}
+	}
+	return keys
+}
+
+// uiTerminal is the terminal of koyeb ui, in raw mode and on the alternate
+// screen. The input is read in the background and sent to input.
+type uiTerminal struct {
+	in    *os.File
+	out   *os.File
+	state *term.State
+	input chan []byte
+}
+
+func newUITerminal() (*uiTerminal, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("the standard input and output must be terminals")
+	}
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	t := &uiTerminal{in: os.Stdin, out: os.Stdout, state: state, input: make(chan []byte)}
+	t.resume()
+
+	go func() {
+		defer close(t.input)
+		buf := make([]byte, 1024)
+		for {
+			n, err := t.in.Read(buf)
+			if n > 0 {
+				t.input <- bytes.Clone(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return t, nil
+}
+
+// Close restores the terminal.
+func (t *uiTerminal) Close() error {
+	t.suspend()
+	return term.Restore(int(t.in.Fd()), t.state)
+}
+
+// suspend leaves the alternate screen, to run a command which uses the
+// terminal. The terminal stays in raw mode.
+func (t *uiTerminal) suspend() {
+	fmt.Fprint(t.out, ansiReset+ansiCursorShow+ansiAltScreenOff)
+}
+
+// resume enters the alternate screen again.
+func (t *uiTerminal) resume() {
+	fmt.Fprint(t.out, ansiAltScreenOn+ansiCursorHide)
+}
+
+// size returns the width and the height of the terminal.
+func (t *uiTerminal) size() (int, int) {
+	width, height, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// draw replaces the content of the screen with lines.
+func (t *uiTerminal) draw(lines []string) {
+	var b strings.Builder
+	b.WriteString(ansiCursorHome)
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString(ansiReset + ansiClearLine)
+	}
+	b.WriteString(ansiClearBelow)
+	io.WriteString(t.out, b.String()) //nolint:errcheck
+}
+
+// stdin returns a reader of the input of the terminal, used to run a command
+// while the UI is suspended. It returns io.EOF once closed, so the input is
+// not consumed after the command exits.
+func (t *uiTerminal) stdin() *uiStdin {
+	return &uiStdin{fd: t.in.Fd(), input: t.input, done: make(chan struct{})}
+}
+
+type uiStdin struct {
+	fd      uintptr
+	input   <-chan []byte
+	done    chan struct{}
+	pending []byte
+}
+
+func (s *uiStdin) Read(p []byte) (int, error) {
+	if len(s.pending) == 0 {
+		select {
+		case <-s.done:
+			return 0, io.EOF
+		default:
+		}
+		select {
+		case <-s.done:
+			return 0, io.EOF
+		case buf, ok := <-s.input:
+			if !ok {
+				return 0, io.EOF
+			}
+			s.pending = buf
+		}
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Fd returns the file descriptor of the terminal, so the command runs in a TTY.
+func (s *uiStdin) Fd() uintptr {
+	return s.fd
+}
+
+func (s *uiStdin) Close() error {
+	close(s.done)
+	return nil
+}
+
+// uiCell pads or truncates s to width characters.
+func uiCell(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
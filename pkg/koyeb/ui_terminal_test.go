@@ -0,0 +1,57 @@
+package koyeb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected []uiKey
+	}{
+		"empty":               {input: "", expected: []uiKey{}},
+		"characters":          {input: "q/", expected: []uiKey{"q", "/"}},
+		"arrows":              {input: "\x1b[A\x1b[B", expected: []uiKey{uiKeyUp, uiKeyDown}},
+		"ss3_arrows":          {input: "\x1bOA\x1bOB", expected: []uiKey{uiKeyUp, uiKeyDown}},
+		"page_keys":           {input: "\x1b[5~\x1b[6~", expected: []uiKey{uiKeyPageUp, uiKeyPageDown}},
+		"home_end":            {input: "\x1b[H\x1b[4~", expected: []uiKey{uiKeyHome, uiKeyEnd}},
+		"unknown_sequence":    {input: "\x1b[1;5Cx", expected: []uiKey{"x"}},
+		"incomplete_sequence": {input: "a\x1b[5", expected: []uiKey{"a"}},
+		"escape":              {input: "\x1b", expected: []uiKey{uiKeyEscape}},
+		"control_keys":        {input: "\r\n\x7f\x08\x03", expected: []uiKey{uiKeyEnter, uiKeyEnter, uiKeyBackspace, uiKeyBackspace, uiKeyCtrlC}},
+		"utf8":                {input: "é世", expected: []uiKey{"é", "世"}},
+		"invalid_utf8":        {input: "\xffa", expected: []uiKey{"a"}},
+		"other_control":       {input: "\x01b", expected: []uiKey{"b"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, decodeKeys([]byte(tc.input)))
+		})
+	}
+}
+
+func TestUICell(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		width    int
+		expected string
+	}{
+		"pad":            {value: "api", width: 5, expected: "api  "},
+		"exact":          {value: "api", width: 3, expected: "api"},
+		"truncate":       {value: "service", width: 4, expected: "ser…"},
+		"width_one":      {value: "service", width: 1, expected: "…"},
+		"width_zero":     {value: "service", width: 0, expected: ""},
+		"utf8_pad":       {value: "café", width: 6, expected: "café  "},
+		"utf8_truncate":  {value: "héllo wörld", width: 6, expected: "héllo…"},
+		"utf8_multibyte": {value: "世界世界", width: 3, expected: "世界…"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, uiCell(tc.value, tc.width))
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package koyeb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/renderer"
+)
+
+// uiListLimit is the maximum number of resources displayed in a list.
+const uiListLimit = "100"
+
+// uiResource is the type of the resources of a list.
+type uiResource string
+
+const (
+	uiOrganizations uiResource = "organizations"
+	uiApps          uiResource = "apps"
+	uiServices      uiResource = "services"
+	uiDeployments   uiResource = "deployments"
+	uiInstances     uiResource = "instances"
+)
+
+// uiRow is a resource of a list.
+type uiRow struct {
+	id string
+	// name identifies the resource in the messages and the confirmations
+	name   string
+	cells  []string
+	status string
+}
+
+// uiView is a list of resources, reloaded periodically to display the live
+// status.
+type uiView struct {
+	resource uiResource
+	title    string
+	headers  []string
+	// statusColumn is the index of the column colored according to the
+	// status, or -1
+	statusColumn int
+	load         func(ctx *CLIContext) ([]uiRow, error)
+
+	rows     []uiRow
+	selected int
+	offset   int
+	loading  bool
+	loadedAt time.Time
+	err      error
+}
+
+// current returns the selected row, or nil if the list is empty.
+func (v *uiView) current() *uiRow {
+	if v.selected < 0 || v.selected >= len(v.rows) {
+		return nil
+	}
+	return &v.rows[v.selected]
+}
+
+// setRows replaces the rows, and keeps the selection on the same resource.
+func (v *uiView) setRows(rows []uiRow) {
+	selectedID := ""
+	if row := v.current(); row != nil {
+		selectedID = row.id
+	}
+	v.rows = rows
+	v.selected = 0
+	for i, row := range rows {
+		if row.id == selectedID {
+			v.selected = i
+		}
+	}
+}
+
+// uiStatusColor returns the color of a status.
+func uiStatusColor(status string) string {
+	switch status {
+	case "HEALTHY", "ACTIVE", "RUNNING", "COMPLETED":
+		return ansiGreen
+	case "ERROR", "ERRORING", "UNHEALTHY", "DEGRADED", "CANCELED", "FAILED":
+		return ansiRed
+	case "PAUSED", "STOPPED", "DELETED", "DEAD", "SLEEPING":
+		return ansiGray
+	default:
+		return ansiYellow
+	}
+}
+
+func newUIOrganizationsView() *uiView {
+	return &uiView{
+		resource:     uiOrganizations,
+		title:        "Organizations",
+		headers:      []string{"name", "plan", "current", "id"},
+		statusColumn: -1,
+		load: func(ctx *CLIContext) ([]uiRow, error) {
+			userId, err := getCurrentUserId(ctx)
+			if err != nil {
+				return nil, err
+			}
+			res, resp, err := ctx.Client.OrganizationMembersApi.ListOrganizationMembers(ctx.Context).
+				UserId(userId).
+				Limit(uiListLimit).
+				Execute()
+			if err != nil {
+				return nil, errors.NewCLIErrorFromAPIError("Error while listing organizations", err, resp)
+			}
+
+			currentOrganization := ctx.Organization
+			if currentOrganization == "" {
+				res, resp, err := ctx.Client.ProfileApi.GetCurrentOrganization(ctx.Context).Execute()
+				if err != nil {
+					return nil, errors.NewCLIErrorFromAPIError("Unable to fetch the current organization", err, resp)
+				}
+				currentOrganization = res.Organization.GetId()
+			}
+
+			rows := []uiRow{}
+			for _, member := range res.GetMembers() {
+				current := ""
+				if member.Organization.GetId() == currentOrganization {
+					current = "✓"
+				}
+				rows = append(rows, uiRow{
+					id:   member.Organization.GetId(),
+					name: member.Organization.GetName(),
+					cells: []string{
+						member.Organization.GetName(),
+						string(member.Organization.GetPlan()),
+						current,
+						renderer.FormatID(member.Organization.GetId(), false),
+					},
+				})
+			}
+			return rows, nil
+		},
+	}
+}
+
+func newUIAppsView() *uiView {
+	return &uiView{
+		resource:     uiApps,
+		title:        "Apps",
+		headers:      []string{"name", "status", "created", "id"},
+		statusColumn: 1,
+		load: func(ctx *CLIContext) ([]uiRow, error) {
+			res, resp, err := ctx.Client.AppsApi.ListApps(ctx.Context).Limit(uiListLimit).Execute()
+			if err != nil {
+				return nil, errors.NewCLIErrorFromAPIError("Error while listing the applications", err, resp)
+			}
+			rows := []uiRow{}
+			for _, app := range res.GetApps() {
+				rows = append(rows, uiRow{
+					id:     app.GetId(),
+					name:   app.GetName(),
+					status: string(app.GetStatus()),
+					cells: []string{
+						app.GetName(),
+						string(app.GetStatus()),
+						renderer.FormatTime(app.GetCreatedAt()),
+						renderer.FormatID(app.GetId(), false),
+					},
+				})
+			}
+			return rows, nil
+		},
+	}
+}
+
+func newUIServicesView(app uiRow) *uiView {
+	return &uiView{
+		resource:     uiServices,
+		title:        fmt.Sprintf("Services of %s", app.name),
+		headers:      []string{"name", "type", "status", "updated", "id"},
+		statusColumn: 2,
+		load: func(ctx *CLIContext) ([]uiRow, error) {
+			res, resp, err := ctx.Client.ServicesApi.ListServices(ctx.Context).AppId(app.id).Limit(uiListLimit).Execute()
+			if err != nil {
+				return nil, errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while listing the services of the application `%s`", app.name), err, resp)
+			}
+			rows := []uiRow{}
+			for _, service := range res.GetServices() {
+				rows = append(rows, uiRow{
+					id:     service.GetId(),
+					name:   fmt.Sprintf("%s/%s", app.name, service.GetName()),
+					status: string(service.GetStatus()),
+					cells: []string{
+						service.GetName(),
+						strings.ToLower(string(service.GetType())),
+						string(service.GetStatus()),
+						renderer.FormatTime(service.GetUpdatedAt()),
+						renderer.FormatID(service.GetId(), false),
+					},
+				})
+			}
+			return rows, nil
+		},
+	}
+}
+
+func newUIDeploymentsView(service uiRow) *uiView {
+	return &uiView{
+		resource:     uiDeployments,
+		title:        fmt.Sprintf("Deployments of %s", service.name),
+		headers:      []string{"id", "status", "created", "messages"},
+		statusColumn: 1,
+		load: func(ctx *CLIContext) ([]uiRow, error) {
+			res, resp, err := ctx.Client.DeploymentsApi.ListDeployments(ctx.Context).ServiceId(service.id).Limit(uiListLimit).Execute()
+			if err != nil {
+				return nil, errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while listing the deployments of the service `%s`", service.name), err, resp)
+			}
+			rows := []uiRow{}
+			for _, deployment := range res.GetDeployments() {
+				rows = append(rows, uiRow{
+					id:     deployment.GetId(),
+					name:   renderer.FormatID(deployment.GetId(), false),
+					status: string(deployment.GetStatus()),
+					cells: []string{
+						renderer.FormatID(deployment.GetId(), false),
+						string(deployment.GetStatus()),
+						renderer.FormatTime(deployment.GetCreatedAt()),
+						strings.Join(deployment.GetMessages(), " "),
+					},
+				})
+			}
+			return rows, nil
+		},
+	}
+}
+
+// newUIInstancesView lists the instances of a service or of a deployment.
+func newUIInstancesView(parent uiRow, resource uiResource) *uiView {
+	return &uiView{
+		resource:     uiInstances,
+		title:        fmt.Sprintf("Instances of %s", parent.name),
+		headers:      []string{"id", "region", "status", "created", "messages"},
+		statusColumn: 2,
+		load: func(ctx *CLIContext) ([]uiRow, error) {
+			query := ctx.Client.InstancesApi.ListInstances(ctx.Context).Limit(uiListLimit)
+			if resource == uiDeployments {
+				query = query.DeploymentId(parent.id)
+			} else {
+				query = query.ServiceId(parent.id)
+			}
+			res, resp, err := query.Execute()
+			if err != nil {
+				return nil, errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while listing the instances of `%s`", parent.name), err, resp)
+			}
+			rows := []uiRow{}
+			for _, instance := range res.GetInstances() {
+				rows = append(rows, uiRow{
+					id:     instance.GetId(),
+					name:   renderer.FormatID(instance.GetId(), false),
+					status: string(instance.GetStatus()),
+					cells: []string{
+						renderer.FormatID(instance.GetId(), false),
+						instance.GetRegion(),
+						string(instance.GetStatus()),
+						renderer.FormatTime(instance.GetCreatedAt()),
+						strings.Join(instance.GetMessages(), " "),
+					},
+				})
+			}
+			return rows, nil
+		},
+	}
+}
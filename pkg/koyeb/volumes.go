@@ -103,9 +103,10 @@ func NewVolumeCmd() *cobra.Command {
 	volumeCmd.AddCommand(createVolumeCmd)
 
 	getVolumeCmd := &cobra.Command{
-		Use:   "get NAME",
-		Short: "Get a volume",
-		Args:  cobra.ExactArgs(1),
+		Use:               "get NAME",
+		Short:             "Get a volume",
+		ValidArgsFunction: completeArgs(completeVolumes),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			return h.Get(ctx, cmd, args)
 		}),
@@ -122,9 +123,10 @@ func NewVolumeCmd() *cobra.Command {
 	volumeCmd.AddCommand(listVolumeCmd)
 
 	updateVolumeCmd := &cobra.Command{
-		Use:   "update NAME",
-		Short: "Update a volume",
-		Args:  cobra.ExactArgs(1),
+		Use:               "update NAME",
+		Short:             "Update a volume",
+		ValidArgsFunction: completeArgs(completeVolumes),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			req := koyeb.NewUpdatePersistentVolumeRequestWithDefaults()
 
@@ -149,9 +151,10 @@ func NewVolumeCmd() *cobra.Command {
 	volumeCmd.AddCommand(updateVolumeCmd)
 
 	deleteVolumeCmd := &cobra.Command{
-		Use:   "delete NAME",
-		Short: "Delete a volume",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete NAME",
+		Short:             "Delete a volume",
+		ValidArgsFunction: completeArgs(completeVolumes),
+		Args:              cobra.ExactArgs(1),
 		RunE: WithCLIContext(func(ctx *CLIContext, cmd *cobra.Command, args []string) error {
 			return h.Delete(ctx, cmd, args)
 		}),
@@ -0,0 +1,333 @@
+package koyeb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/koyeb/koyeb-cli/pkg/koyeb/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// WaitExitCodeTimeout is the exit code of `koyeb wait` when the timeout is reached.
+	WaitExitCodeTimeout = 2
+	// WaitExitCodeUnreachable is the exit code of `koyeb wait` when the
+	// resource reached a final status which does not match the condition.
+	WaitExitCodeUnreachable = 3
+)
+
+func NewWaitCmd() *cobra.Command {
+	h := NewWaitHandler()
+
+	waitCmd := &cobra.Command{
+		Use:   "wait RESOURCE",
+		Short: "Wait for a resource to reach a status",
+		Long: `Wait for a resource to reach a status.
+
+The command exits with the following codes:
+  0: the condition has been met
+  1: an error occurred, for example the resource does not exist
+  2: the timeout has been reached before the condition was met
+  3: the resource reached a final status which does not match the condition, for example a deployment in ERROR when waiting for HEALTHY`,
+	}
+
+	addWaitFlags := func(cmd *cobra.Command) {
+		cmd.Flags().String("for", "", "Condition to wait for, in the format status=STATUS. Separate statuses with | to wait for any of them, for example status=HEALTHY|ERROR")
+		cmd.Flags().Duration("timeout", 5*time.Minute, "Duration after which the command gives up")
+		cmd.Flags().Duration("interval", 2*time.Second, "Duration between two status checks")
+		_ = cmd.MarkFlagRequired("for")
+	}
+
+	waitServiceCmd := &cobra.Command{
+		Use:               "service NAME",
+		Short:             "Wait for a service to reach a status",
+		ValidArgsFunction: completeArgs(completeServices),
+		Args:              cobra.ExactArgs(1),
+		Example: `
+# Wait until the service "myservice" is healthy
+$> koyeb wait service myapp/myservice --for status=HEALTHY --timeout 10m
+`,
+		RunE: WithCLIContext(h.WaitService),
+	}
+	addWaitFlags(waitServiceCmd)
+	waitServiceCmd.Flags().StringP("app", "a", "", "Service application")
+	waitCmd.AddCommand(waitServiceCmd)
+
+	waitDeploymentCmd := &cobra.Command{
+		Use:               "deployment NAME",
+		Short:             "Wait for a deployment to reach a status",
+		ValidArgsFunction: completeArgs(completeDeployments),
+		Args:              cobra.ExactArgs(1),
+		Example: `
+# Wait until the deployment is either healthy or in error
+$> koyeb wait deployment 1f8a3d2c --for 'status=HEALTHY|ERROR'
+`,
+		RunE: WithCLIContext(h.WaitDeployment),
+	}
+	addWaitFlags(waitDeploymentCmd)
+	waitCmd.AddCommand(waitDeploymentCmd)
+
+	waitDomainCmd := &cobra.Command{
+		Use:               "domain NAME",
+		Short:             "Wait for a domain to reach a status",
+		ValidArgsFunction: completeArgs(completeDomains),
+		Args:              cobra.ExactArgs(1),
+		Example: `
+# Wait until the domain is active
+$> koyeb wait domain www.example.com --for status=ACTIVE
+`,
+		RunE: WithCLIContext(h.WaitDomain),
+	}
+	addWaitFlags(waitDomainCmd)
+	waitCmd.AddCommand(waitDomainCmd)
+
+	waitDatabaseCmd := &cobra.Command{
+		Use:               "database NAME",
+		Short:             "Wait for a database to reach a status",
+		ValidArgsFunction: completeArgs(completeDatabases),
+		Args:              cobra.ExactArgs(1),
+		Example: `
+# Wait until the database is ready to accept connections
+$> koyeb wait database myapp/mydb --for status=HEALTHY
+`,
+		RunE: WithCLIContext(h.WaitDatabase),
+	}
+	addWaitFlags(waitDatabaseCmd)
+	waitDatabaseCmd.Flags().StringP("app", "a", "", "Database application")
+	waitCmd.AddCommand(waitDatabaseCmd)
+
+	return waitCmd
+}
+
+func NewWaitHandler() *WaitHandler {
+	return &WaitHandler{}
+}
+
+type WaitHandler struct {
+}
+
+// waitTarget describes a resource to wait for.
+type waitTarget struct {
+	// Kind and name of the resource, used in messages
+	kind string
+	name string
+	// All the statuses the resource can have
+	statuses []string
+	// Statuses the resource never leaves
+	finalStatuses []string
+	// Returns the current status of the resource
+	getStatus func(ctx context.Context) (string, error)
+}
+
+func (h *WaitHandler) WaitService(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	serviceHandler := NewServiceHandler()
+	serviceName, err := serviceHandler.parseServiceName(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	service, err := serviceHandler.ResolveServiceArgs(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	return h.wait(ctx, cmd, waitTarget{
+		kind:          "service",
+		name:          serviceName,
+		statuses:      enumToStrings(koyeb.AllowedServiceStatusEnumValues),
+		finalStatuses: []string{string(koyeb.SERVICESTATUS_DELETED)},
+		getStatus: func(ctxd context.Context) (string, error) {
+			res, resp, err := ctx.Client.ServicesApi.GetService(ctxd, service).Execute()
+			if err != nil {
+				return "", errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while retrieving the service `%s`", serviceName), err, resp)
+			}
+			return string(res.Service.GetStatus()), nil
+		},
+	})
+}
+
+func (h *WaitHandler) WaitDeployment(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	deployment, err := NewDeploymentHandler().ResolveDeploymentArgs(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	return h.wait(ctx, cmd, waitTarget{
+		kind:     "deployment",
+		name:     args[0],
+		statuses: enumToStrings(koyeb.AllowedDeploymentStatusEnumValues),
+		finalStatuses: []string{
+			string(koyeb.DEPLOYMENTSTATUS_CANCELED),
+			string(koyeb.DEPLOYMENTSTATUS_STOPPED),
+			string(koyeb.DEPLOYMENTSTATUS_ERROR),
+		},
+		getStatus: func(ctxd context.Context) (string, error) {
+			res, resp, err := ctx.Client.DeploymentsApi.GetDeployment(ctxd, deployment).Execute()
+			if err != nil {
+				return "", errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while retrieving the deployment `%s`", args[0]), err, resp)
+			}
+			return string(res.Deployment.GetStatus()), nil
+		},
+	})
+}
+
+func (h *WaitHandler) WaitDomain(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	domain, err := NewDomainHandler().ResolveDomainArgs(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	return h.wait(ctx, cmd, waitTarget{
+		kind:          "domain",
+		name:          args[0],
+		statuses:      enumToStrings(koyeb.AllowedDomainStatusEnumValues),
+		finalStatuses: []string{string(koyeb.DOMAINSTATUS_DELETED)},
+		getStatus: func(ctxd context.Context) (string, error) {
+			res, resp, err := ctx.Client.DomainsApi.GetDomain(ctxd, domain).Execute()
+			if err != nil {
+				return "", errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while retrieving the domain `%s`", args[0]), err, resp)
+			}
+			return string(res.Domain.GetStatus()), nil
+		},
+	})
+}
+
+func (h *WaitHandler) WaitDatabase(ctx *CLIContext, cmd *cobra.Command, args []string) error {
+	databaseName, err := NewServiceHandler().parseServiceName(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	database, err := NewDatabaseHandler().ResolveDatabaseArgs(ctx, databaseName)
+	if err != nil {
+		return err
+	}
+
+	return h.wait(ctx, cmd, waitTarget{
+		kind:          "database",
+		name:          databaseName,
+		statuses:      enumToStrings(koyeb.AllowedServiceStatusEnumValues),
+		finalStatuses: []string{string(koyeb.SERVICESTATUS_DELETED)},
+		getStatus: func(ctxd context.Context) (string, error) {
+			res, resp, err := ctx.Client.ServicesApi.GetService(ctxd, database).Execute()
+			if err != nil {
+				return "", errors.NewCLIErrorFromAPIError(fmt.Sprintf("Error while retrieving the database `%s`", databaseName), err, resp)
+			}
+			return string(res.Service.GetStatus()), nil
+		},
+	})
+}
+
+// wait polls the status of the target until it matches the --for condition, or until --timeout is reached.
+func (h *WaitHandler) wait(ctx *CLIContext, cmd *cobra.Command, target waitTarget) error {
+	expected, err := parseWaitCondition(GetStringFlags(cmd, "for"), target.statuses)
+	if err != nil {
+		return err
+	}
+	timeout := GetDurationFlags(cmd, "timeout")
+	interval := GetDurationFlags(cmd, "interval")
+
+	ctxd, cancel := context.WithTimeout(ctx.Context, timeout)
+	defer cancel()
+
+	previousStatus := ""
+	// check returns true when waiting is over, either because the condition has been met or because it never will be.
+	check := func() (bool, error) {
+		status, err := target.getStatus(ctxd)
+		if err != nil {
+			// The request has been interrupted by the timeout
+			if ctxd.Err() != nil {
+				return false, nil
+			}
+			return true, err
+		}
+		if status != previousStatus {
+			log.Infof("The %s `%s` is %s", target.kind, target.name, status)
+			previousStatus = status
+		}
+		if slices.Contains(expected, status) {
+			return true, nil
+		}
+		if slices.Contains(target.finalStatuses, status) {
+			return true, &errors.CLIError{
+				What: fmt.Sprintf("Error while waiting for the %s `%s`", target.kind, target.name),
+				Why:  fmt.Sprintf("the %s reached the status %s, which it will not leave", target.kind, status),
+				Additional: []string{
+					fmt.Sprintf("Expected status: %s", strings.Join(expected, " or ")),
+				},
+				Orig:     nil,
+				Solution: "Check the status of the resource, and try again",
+				ExitCode: WaitExitCodeUnreachable,
+			}
+		}
+		return false, nil
+	}
+
+	if done, err := check(); done {
+		return err
+	}
+	for range ticker(ctxd, interval) {
+		if done, err := check(); done {
+			return err
+		}
+	}
+
+	return &errors.CLIError{
+		What: fmt.Sprintf("Error while waiting for the %s `%s`", target.kind, target.name),
+		Why:  fmt.Sprintf("the timeout of %s has been reached", timeout),
+		Additional: []string{
+			fmt.Sprintf("Current status: %s", previousStatus),
+			fmt.Sprintf("Expected status: %s", strings.Join(expected, " or ")),
+		},
+		Orig:     nil,
+		Solution: "Increase the timeout with --timeout, or check the status of the resource",
+		ExitCode: WaitExitCodeTimeout,
+	}
+}
+
+// parseWaitCondition parses the --for flag, which has the format status=STATUS[|STATUS...].
+// It returns the list of expected statuses, in upper case.
+func parseWaitCondition(condition string, allowed []string) ([]string, error) {
+	key, value, found := strings.Cut(condition, "=")
+	// status=| and status=, have no status once the separators are removed
+	statuses := strings.FieldsFunc(value, func(r rune) bool { return r == '|' || r == ',' })
+	if !found || strings.TrimSpace(key) != "status" || len(statuses) == 0 {
+		return nil, &errors.CLIError{
+			What:       "Error while parsing the --for flag",
+			Why:        fmt.Sprintf("the condition %q is invalid", condition),
+			Additional: []string{"The condition must be in the format status=STATUS, for example status=HEALTHY"},
+			Orig:       nil,
+			Solution:   "Fix the --for flag and try again",
+		}
+	}
+
+	ret := []string{}
+	for _, status := range statuses {
+		status = strings.ToUpper(strings.TrimSpace(status))
+		if !slices.Contains(allowed, status) {
+			return nil, &errors.CLIError{
+				What:       "Error while parsing the --for flag",
+				Why:        fmt.Sprintf("the status %q is invalid", status),
+				Additional: []string{fmt.Sprintf("Valid statuses are: %s", strings.Join(allowed, ", "))},
+				Orig:       nil,
+				Solution:   "Fix the --for flag and try again",
+			}
+		}
+		ret = append(ret, status)
+	}
+	return ret, nil
+}
+
+// enumToStrings converts the values of an enum of the API client to strings.
+func enumToStrings[T ~string](values []T) []string {
+	ret := make([]string, 0, len(values))
+	for _, value := range values {
+		ret = append(ret, string(value))
+	}
+	return ret
+}
@@ -0,0 +1,42 @@
+package koyeb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWaitCondition(t *testing.T) {
+	allowed := []string{"HEALTHY", "DEGRADED", "STOPPED"}
+
+	tests := map[string]struct {
+		condition string
+		expected  []string
+		err       string
+	}{
+		"single":         {condition: "status=HEALTHY", expected: []string{"HEALTHY"}},
+		"lower_case":     {condition: "status=healthy", expected: []string{"HEALTHY"}},
+		"pipe":           {condition: "status=HEALTHY|DEGRADED", expected: []string{"HEALTHY", "DEGRADED"}},
+		"comma":          {condition: "status=HEALTHY,STOPPED", expected: []string{"HEALTHY", "STOPPED"}},
+		"spaces":         {condition: " status = healthy | stopped ", expected: []string{"HEALTHY", "STOPPED"}},
+		"no_equal":       {condition: "HEALTHY", err: `the condition "HEALTHY" is invalid`},
+		"unknown_key":    {condition: "phase=HEALTHY", err: `the condition "phase=HEALTHY" is invalid`},
+		"empty_value":    {condition: "status=", err: `the condition "status=" is invalid`},
+		"only_pipe":      {condition: "status=|", err: `the condition "status=|" is invalid`},
+		"only_comma":     {condition: "status=,", err: `the condition "status=," is invalid`},
+		"unknown_status": {condition: "status=HEALTHY|RUNNING", err: `the status "RUNNING" is invalid`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			statuses, err := parseWaitCondition(tc.condition, allowed)
+			if tc.err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, statuses)
+		})
+	}
+}